@@ -1,45 +1,309 @@
-package emacs
-
-import (
-	"fmt"
-	"strings"
-)
-
-func basic(debugInit bool, fos ...*fileOpts) (string, error) {
-	r := make([]string, 0, 1+2*len(fos))
-	r = append(r, "emacs", "--no-window-system")
-	if debugInit {
-		r = append(r, "--debug-init")
-	}
-	// Reverse order.
-	for i := len(fos) - 1; i >= 0; i-- {
-		f := fos[i]
-		if f.lineNumber != 0 {
-			r = append(r, fmt.Sprintf("+%d", f.lineNumber))
-		}
-		r = append(r, f.name)
-	}
-
-	return strings.Join(r, " "), nil
-}
-
-func daemon(debugInit bool, fos ...*fileOpts) (string, error) {
-	if debugInit {
-		return "", fmt.Errorf("--debug-init flag is not allowed in daemon mode")
-	}
-	var eCmds []string
-	findCmd := "find-file"
-	for _, fo := range fos {
-		eCmds = append(eCmds, fmt.Sprintf(`(%s "%s")`, findCmd, fo.name))
-		if fo.lineNumber != 0 {
-			eCmds = append(eCmds, fmt.Sprintf(`(goto-line %d)`, fo.lineNumber))
-		}
-		findCmd = "find-file-other-window"
-	}
-	if len(fos) == 2 {
-		eCmds = append(eCmds, `(other-window 1)`)
-	}
-
-	// TODO: add daemon initializer code.
-	return fmt.Sprintf("emacsclient -t -e '(progn %s)'", strings.Join(eCmds, "")), nil
-}
+package emacs
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// editorOpts contains options that apply to the entire editor command
+// (as opposed to fileOpts which only apply to an individual file).
+type editorOpts struct {
+	debugInit bool
+	// gui is whether or not the files are opened in a graphical frame.
+	gui bool
+	// monitor is the index of the monitor to position the frame on (if set).
+	monitor *int
+	// chdir is whether or not to open daemon files from their own directory.
+	chdir bool
+	// minorModes are the minor modes to enable in every opened file.
+	minorModes []string
+	// binary is the path to the emacs binary (if empty, then the one in the
+	// PATH is used).
+	binary string
+	// socket is the name of the daemon's server socket (if empty, then the
+	// default socket is used).
+	socket string
+	// normalOrder is whether or not basic mode opens files in the provided
+	// order (rather than in reverse).
+	normalOrder bool
+	// noWait is whether or not the daemon client returns immediately
+	// (instead of waiting for the buffers to be closed).
+	noWait bool
+	// extraFlags are additional flags passed to emacs in basic mode.
+	extraFlags []string
+	// evals are elisp forms that are evaluated after the files are opened.
+	evals []string
+	// passthrough are arguments passed to emacs verbatim in basic mode.
+	passthrough []string
+}
+
+// emacs returns the emacs command to run.
+func (eo *editorOpts) emacs() string {
+	if eo.binary == "" {
+		return "emacs"
+	}
+	return eo.binary
+}
+
+// emacsclient returns the emacsclient command to run. A configured binary is
+// expected to live in the same directory as its emacsclient.
+func (eo *editorOpts) emacsclient() string {
+	if eo.binary == "" {
+		return "emacsclient"
+	}
+	return filepath.Join(filepath.Dir(eo.binary), "emacsclient")
+}
+
+// client returns the emacsclient command, including the socket to connect to.
+func (eo *editorOpts) client() string {
+	if eo.socket == "" {
+		return eo.emacsclient()
+	}
+	return fmt.Sprintf("%s -s %s", eo.emacsclient(), eo.socket)
+}
+
+// daemonFlag returns the flag for starting the daemon.
+func (eo *editorOpts) daemonFlag() string {
+	if eo.socket == "" {
+		return "--daemon"
+	}
+	return fmt.Sprintf("--daemon=%s", eo.socket)
+}
+
+// monitorElisp returns elisp that moves the selected frame onto the monitor
+// at the provided index. Nothing is done if the monitor doesn't exist.
+func monitorElisp(idx int) string {
+	return fmt.Sprintf(`(let ((geometry (cdr (assq (quote geometry) (nth %d (display-monitor-attributes-list)))))) (when geometry (set-frame-position (selected-frame) (nth 0 geometry) (nth 1 geometry))))`, idx)
+}
+
+func basic(eo *editorOpts, fos ...*fileOpts) (string, error) {
+	r := make([]string, 0, 1+2*len(fos))
+	r = append(r, eo.emacs())
+	if !eo.gui {
+		r = append(r, "--no-window-system")
+	}
+	r = append(r, eo.extraFlags...)
+	if eo.debugInit {
+		r = append(r, "--debug-init")
+	}
+	if eo.gui && eo.monitor != nil {
+		r = append(r, "--eval", fmt.Sprintf("'%s'", monitorElisp(*eo.monitor)))
+	}
+	// Reverse order (unless otherwise configured) so the first file is the
+	// active buffer.
+	for i := range fos {
+		f := fos[len(fos)-1-i]
+		if eo.normalOrder {
+			f = fos[i]
+		}
+		if f.shellCommand != "" {
+			r = append(r, "--eval", fmt.Sprintf("'%s'", quoteEscape(fmt.Sprintf("(progn %s)", shellCommandElisp(f, "switch-to-buffer")))))
+			continue
+		}
+		if f.bookmark != "" {
+			r = append(r, "--eval", fmt.Sprintf("'%s'", quoteEscape(bookmarkJumpElisp(f, "bookmark-jump"))))
+			continue
+		}
+		if f.buffer != "" {
+			r = append(r, "--eval", fmt.Sprintf("'%s'", quoteEscape(fmt.Sprintf("(switch-to-buffer %q)", f.buffer))))
+			continue
+		}
+		// There isn't a command line option for opening a file read-only,
+		// for searching in a file, or for moving relative to the file's size.
+		if f.readOnly || f.symbol != "" || f.end || f.percent != nil || f.lineNumber < 0 {
+			findCmd := "find-file"
+			if f.readOnly {
+				findCmd = "find-file-read-only"
+			}
+			cmds := append([]string{fmt.Sprintf(`(%s "%s")`, findCmd, f.name)}, positionElisp(f)...)
+			find := cmds[0]
+			if len(cmds) > 1 {
+				find = fmt.Sprintf("(progn %s)", strings.Join(cmds, " "))
+			}
+			r = append(r, "--eval", fmt.Sprintf("'%s'", find))
+			continue
+		}
+		if f.lineNumber != 0 && f.column != 0 {
+			r = append(r, fmt.Sprintf("+%d:%d", f.lineNumber, f.column))
+		} else if f.lineNumber != 0 {
+			r = append(r, fmt.Sprintf("+%d", f.lineNumber))
+		}
+		r = append(r, f.name)
+	}
+	if len(fos) == 2 {
+		// Show the file that isn't the active buffer in a window to its right.
+		other := fos[1]
+		if eo.normalOrder {
+			other = fos[0]
+		}
+		buffer := fmt.Sprintf(`(get-file-buffer "%s")`, other.name)
+		if other.shellCommand != "" {
+			buffer = quoteEscape(fmt.Sprintf("%q", shellCommandBuffer(other)))
+		}
+		if other.bookmark != "" {
+			buffer = quoteEscape(fmt.Sprintf("(get-file-buffer (bookmark-get-filename %q))", other.bookmark))
+		}
+		if other.buffer != "" {
+			buffer = quoteEscape(fmt.Sprintf("%q", other.buffer))
+		}
+		r = append(r, "--eval", fmt.Sprintf(`'(progn (split-window-right) (other-window 1) (switch-to-buffer %s) (other-window 1))'`, buffer))
+	}
+	if len(eo.minorModes) > 0 {
+		var modes []string
+		for _, m := range eo.minorModes {
+			modes = append(modes, fmt.Sprintf("(%s 1)", m))
+		}
+		r = append(r, "--eval", fmt.Sprintf("'(dolist (b (buffer-list)) (with-current-buffer b (when buffer-file-name %s)))'", strings.Join(modes, " ")))
+	}
+	for _, ev := range eo.evals {
+		r = append(r, "--eval", fmt.Sprintf("'%s'", quoteEscape(ev)))
+	}
+	r = append(r, eo.passthrough...)
+
+	return strings.Join(r, " "), nil
+}
+
+// quoteEscape escapes single quotes so the provided string can be included
+// in a single-quoted shell argument.
+func quoteEscape(s string) string {
+	return strings.ReplaceAll(s, "'", `'\''`)
+}
+
+// positionElisp returns the elisp commands for moving to the file's line,
+// column, and symbol (if provided).
+func positionElisp(f *fileOpts) []string {
+	var cmds []string
+	switch {
+	case f.end:
+		cmds = append(cmds, `(goto-char (point-max))`)
+	case f.percent != nil:
+		cmds = append(cmds, fmt.Sprintf(`(goto-char (/ (* %d (point-max)) 100))`, *f.percent))
+	case f.lineNumber < 0:
+		// Negative line numbers count up from the end of the file (so -1 is
+		// the last line when the file ends with a newline). A line number of
+		// 0 is the same as not providing one.
+		cmds = append(cmds, `(goto-char (point-max))`, fmt.Sprintf(`(forward-line %d)`, f.lineNumber))
+	case f.lineNumber > 0:
+		cmds = append(cmds, fmt.Sprintf(`(goto-line %d)`, f.lineNumber))
+	}
+	if !f.end && f.percent == nil && f.lineNumber != 0 && f.column != 0 {
+		cmds = append(cmds, fmt.Sprintf(`(move-to-column %d)`, f.column))
+	}
+	if f.symbol != "" {
+		// Search from the line if one was provided.
+		if f.lineNumber == 0 {
+			cmds = append(cmds, `(goto-char (point-min))`)
+		}
+		cmds = append(cmds, fmt.Sprintf(`(re-search-forward "%s")`, f.symbol))
+	}
+	return cmds
+}
+
+// shellCommandBuffer returns the name of the buffer that the output of the
+// file's shell command is written to.
+func shellCommandBuffer(fo *fileOpts) string {
+	return fmt.Sprintf("*%s*", fo.shellCommand)
+}
+
+// shellCommandElisp returns the elisp that runs the file's shell command and
+// then switches to its output buffer with the provided command.
+func shellCommandElisp(fo *fileOpts, switchCmd string) string {
+	buffer := shellCommandBuffer(fo)
+	return fmt.Sprintf("(shell-command %q %q)(%s %q)", fo.shellCommand, buffer, switchCmd, buffer)
+}
+
+// bookmarkJumpElisp returns the elisp that jumps to the file's bookmark with
+// the provided command.
+func bookmarkJumpElisp(fo *fileOpts, jumpCmd string) string {
+	return fmt.Sprintf("(%s %q)", jumpCmd, fo.bookmark)
+}
+
+func daemon(eo *editorOpts, fos ...*fileOpts) (string, error) {
+	if eo.debugInit {
+		return "", fmt.Errorf("--debug-init flag is not allowed in daemon mode")
+	}
+	var eCmds []string
+	if eo.gui && eo.monitor != nil {
+		eCmds = append(eCmds, monitorElisp(*eo.monitor))
+	}
+	// The first file is the active buffer (as in basic mode), unless normal
+	// order is configured, in which case the last file is the active buffer.
+	if eo.normalOrder {
+		rev := make([]*fileOpts, 0, len(fos))
+		for i := range fos {
+			rev = append(rev, fos[len(fos)-1-i])
+		}
+		fos = rev
+	}
+	otherWindow := false
+	for _, fo := range fos {
+		// Shell command output is opened in a buffer named after the command.
+		if fo.shellCommand != "" {
+			switchCmd := "switch-to-buffer"
+			if otherWindow {
+				switchCmd += "-other-window"
+			}
+			otherWindow = true
+			eCmds = append(eCmds, quoteEscape(shellCommandElisp(fo, switchCmd)))
+			continue
+		}
+		if fo.bookmark != "" {
+			jumpCmd := "bookmark-jump"
+			if otherWindow {
+				jumpCmd += "-other-window"
+			}
+			otherWindow = true
+			eCmds = append(eCmds, quoteEscape(bookmarkJumpElisp(fo, jumpCmd)))
+			continue
+		}
+		if fo.buffer != "" {
+			switchCmd := "switch-to-buffer"
+			if otherWindow {
+				switchCmd += "-other-window"
+			}
+			otherWindow = true
+			eCmds = append(eCmds, quoteEscape(fmt.Sprintf("(%s %q)", switchCmd, fo.buffer)))
+			continue
+		}
+
+		findCmd := "find-file"
+		if fo.readOnly {
+			findCmd = "find-file-read-only"
+		}
+		if fo.dired {
+			findCmd = "dired"
+		}
+		if otherWindow {
+			findCmd += "-other-window"
+		}
+		otherWindow = true
+
+		find := fmt.Sprintf(`(%s "%s")`, findCmd, fo.name)
+		if eo.chdir {
+			find = fmt.Sprintf(`(let ((default-directory "%s/")) %s)`, filepath.Dir(fo.name), find)
+		}
+		eCmds = append(eCmds, find)
+		eCmds = append(eCmds, positionElisp(fo)...)
+		for _, m := range eo.minorModes {
+			eCmds = append(eCmds, fmt.Sprintf(`(%s 1)`, m))
+		}
+	}
+	if len(fos) == 2 {
+		eCmds = append(eCmds, `(other-window 1)`)
+	}
+	for _, ev := range eo.evals {
+		eCmds = append(eCmds, quoteEscape(ev))
+	}
+
+	frameArg := "-t"
+	if eo.gui {
+		frameArg = "-c"
+	}
+	if eo.noWait {
+		frameArg = "-n " + frameArg
+	}
+
+	// TODO: add daemon initializer code.
+	return fmt.Sprintf("%s %s -e '(progn %s)'", eo.client(), frameArg, strings.Join(eCmds, "")), nil
+}
@@ -0,0 +1,37 @@
+package emacs
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+var (
+	// clip is the clipboard used by the CLI (stubbed out for tests).
+	clip clipboard = &osClipboard{}
+)
+
+// clipboard copies text to the system clipboard.
+type clipboard interface {
+	Copy(s string) error
+}
+
+type osClipboard struct{}
+
+func (*osClipboard) Copy(s string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("pbcopy")
+	case "windows":
+		cmd = exec.Command("clip")
+	default:
+		cmd = exec.Command("xclip", "-selection", "clipboard")
+	}
+	cmd.Stdin = strings.NewReader(s)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%v: %s", err, out)
+	}
+	return nil
+}
@@ -2,304 +2,3358 @@
 package emacs
 
 import (
+	"bufio"
 	"encoding/json"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/leep-frog/command"
 )
 
 const (
-	aliasArg      = "ALIAS"
-	fileArg       = "FILE"
-	emacsArg      = "EMACS_ARG"
-	lineArg       = "LINE_NUMBER"
-	historicalArg = "COMMAND_IDX"
-	regexpArg     = "REGEXP"
-	newFileArg    = "new"
+	aliasArg         = "ALIAS"
+	fileArg          = "FILE"
+	emacsArg         = "EMACS_ARG"
+	lineArg          = "LINE_NUMBER"
+	columnArg        = "COLUMN_NUMBER"
+	lineColArg       = "LINE_COLUMN"
+	symbolArg        = "SYMBOL"
+	atSymbolArg      = "AT_SYMBOL"
+	endArg           = "END_OF_FILE"
+	endTokenArg      = "END_TOKEN"
+	percentArg       = "PERCENT"
+	pctTokenArg      = "PERCENT_TOKEN"
+	bookmarkTokenArg = "BOOKMARK_TOKEN"
+	historicalArg    = "COMMAND_IDX"
+	repeatArg        = "REPEAT_OFFSET"
+	regexpArg        = "REGEXP"
+	grepFileArg      = "GREP_FILE"
+	histFileArg      = "HISTORY_FILE"
+	binaryArg        = "BINARY"
+	rootArg          = "ROOT"
+	limitArg         = "LIMIT"
+	socketArg        = "SOCKET"
+	nocaseArg        = "NOCASE"
+	aliasCaseArg     = "ALIAS_NOCASE"
+	guiArg           = "GUI"
+	extraFlagsArg    = "EXTRA_FLAGS"
+	autoArg          = "AUTO"
+	orderArg         = "ORDER"
+	newAliasArg      = "NEW_ALIAS"
+	recentArg        = "RECENT_IDX"
+	dirArg           = "DIRECTORY"
+	newFileArg       = "new"
+	withArg          = "with"
+	evalArg          = "eval"
+	maxSizeArg       = "MAX_SIZE"
+	extensionArg     = "EXTENSION"
+	extLineArg       = "EXTENSION_LINE"
+	openGroupArg     = "OPEN_GROUP"
+	marksArg         = "MARKS"
+	markTokenArg     = "MARK_TOKEN"
+	shellArg         = "SHELL"
+	passthroughArg   = "PASSTHROUGH"
 
 	fileAliaserName = "fileAliases"
 	cacheName       = "emacsCache"
+
+	// overlayEnvVar is the environment variable pointing to a JSON file of
+	// machine-specific aliases that are overlaid on top of the saved aliases.
+	overlayEnvVar = "EMACS_ALIAS_OVERLAY"
+
+	// listFilePrefix is the prefix for arguments that point to a file
+	// containing a list of files to open.
+	listFilePrefix = "@"
+	// stdinFileList is the argument for reading a list of files (in the same
+	// format as a list file) from stdin.
+	stdinFileList = "-"
+	// shellCommandPrefix is the prefix for arguments (usually alias values)
+	// that are shell commands whose output is opened in a buffer.
+	shellCommandPrefix = "!"
+	// bookmarkPrefix is the prefix for arguments that are emacs bookmark
+	// names. It is checked before listFilePrefix.
+	bookmarkPrefix = "@@"
+	// globChars are the characters that make an argument a glob pattern.
+	globChars = "*?["
+	// scratchBuffer is the buffer opened by the scratch command.
+	scratchBuffer = "*scratch*"
+	// sudoPrefix is the TRAMP prefix for editing files as root.
+	sudoPrefix = "/sudo::"
+	// blameElisp annotates the current buffer with version control info.
+	blameElisp = "(vc-annotate (buffer-file-name) (vc-working-revision (buffer-file-name)))"
+	// bashCompletion hooks the CLI into bash completion. It uses the same
+	// function (and binary) that the command package's sourcerer generates.
+	bashCompletion = `function _custom_autocomplete {
+  tFile=$(mktemp)
+  $GOPATH/bin/leep-frog-source autocomplete $COMP_CWORD.$COMP_POINT $COMP_LINE > $tFile
+  local IFS=$'\n'
+  COMPREPLY=( $(cat $tFile) )
+  rm $tFile
+}
+complete -F _custom_autocomplete -o nosort %s`
+	// zshCompletion is the same as bashCompletion, but first enables bash
+	// completion functions in zsh.
+	zshCompletion = "autoload -U +X bashcompinit && bashcompinit\n" + bashCompletion
+	// historyTimeFormat is the format used when listing history timestamps.
+	historyTimeFormat = "2006-01-02 15:04:05"
 )
 
 var (
+	// minorModeRegex matches valid minor mode symbols.
+	minorModeRegex = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9\-_/+*.]*$`)
+	// lineColRegex matches LINE:COLUMN arguments.
+	lineColRegex = regexp.MustCompile(`^([0-9]+):([0-9]+)$`)
+	// fileLineRegex matches FILE:LINE arguments.
+	fileLineRegex = regexp.MustCompile(`^(.+):([0-9]+)$`)
+	// relativeLineRegex matches +N and -N arguments that shift the line
+	// numbers of the cached command.
+	relativeLineRegex = regexp.MustCompile(`^[+-][0-9]+$`)
+	// fileLineColRegex matches FILE:LINE:COLUMN arguments.
+	fileLineColRegex = regexp.MustCompile(`^(.+):([0-9]+):([0-9]+)$`)
+	// partialFileLineRegex matches FILE:LINE and FILE:LINE:COLUMN arguments
+	// that are being completed (so the last number may be empty).
+	partialFileLineRegex = regexp.MustCompile(`^(.+?)((?::[0-9]+)?:[0-9]*)$`)
+	// percentRegex matches %N arguments.
+	percentRegex = regexp.MustCompile(`^%([0-9]+)$`)
+	// symbolRegex matches @symbol arguments.
+	symbolRegex = regexp.MustCompile(`^@([a-zA-Z_][a-zA-Z0-9_]*)$`)
+	// trampRegex matches TRAMP remote paths (e.g. /ssh:host:/etc/hosts).
+	trampRegex = regexp.MustCompile(`^/[a-zA-Z][a-zA-Z0-9\-]*:[^/]*:`)
+
 	// This is in the var section so it can be stubbed out for tests.
 	historyLimit = 25
+	// Stubbed out for tests.
+	stdin       io.Reader = os.Stdin
+	lookPath              = exec.LookPath
+	getuid                = os.Getuid
+	getgroups             = os.Getgroups
+	getenv                = os.Getenv
+	userHomeDir           = os.UserHomeDir
+	run                   = command.Run
+	now                   = time.Now
+	getwd                 = os.Getwd
 
+	newFileFlag   = command.BoolFlag(newFileArg, 'n')
 	debugInitFlag = command.BoolFlag("debugInit", 'd')
+	quietFlag     = command.BoolFlag("quiet", 'q')
+	groupFlag     = command.StringFlag("group", 'G', nil)
+	forceFlag     = command.BoolFlag("force", 'f')
+	checkFlag     = command.BoolFlag("check", 'k')
+	writableFlag  = command.BoolFlag("writable", 'W')
+	readOnlyFlag  = command.BoolFlag("readonly", 'r')
+	dryRunFlag    = command.BoolFlag("dry-run", 'y')
+	sudoFlag      = command.BoolFlag("sudo", 'S')
+	newFrameFlag  = command.BoolFlag("new-frame", 'c')
+	noWaitFlag    = command.BoolFlag("nowait", 'N')
+	verboseFlag   = command.BoolFlag("verbose", 'v')
+	clearFlag     = command.BoolFlag("clear", 'c')
+	allFlag       = command.BoolFlag("all", 'a')
+	rankFlag      = command.BoolFlag("rank", 'R')
+	forceOpenFlag = command.BoolFlag("force-open", 'O')
+	largeFlag     = command.BoolFlag("large", 'l')
+	countFlag     = command.BoolFlag("count", 'u')
+	unusedFlag    = command.BoolFlag("unused", 'U')
+	blameFlag     = command.BoolFlag("blame", 'b')
+	globalFlag    = command.BoolFlag("global", 'g')
+	marksFlag     = command.BoolFlag("marks", 'M')
+	touchFlag     = command.BoolFlag("touch", 'T')
+	diredFlag     = command.BoolFlag("dir", 'D')
+	outFlag       = command.StringFlag("out", 'o', &command.ArgOpt{
+		Completor: &command.Completor{
+			SuggestionFetcher: &command.FileFetcher{},
+		},
+		Transformer: command.FileTransformer(),
+	})
+	copyPathFlag  = command.BoolFlag("copy-path", 'C')
+	noHistoryFlag = command.BoolFlag("no-history", 'H')
+	depthFlag     = command.IntFlag("depth", 'L', &command.ArgOpt{
+		Validators: []command.ArgValidator{command.IntNonNegative()},
+	})
+	monitorFlag = command.IntFlag("monitor", 'm', &command.ArgOpt{
+		Validators: []command.ArgValidator{command.IntNonNegative()},
+	})
+	// evalFlag can be provided multiple times to evaluate multiple forms.
+	evalFlag = command.StringListFlag(evalArg, 'E', 1, 0, &command.ArgOpt{
+		CustomSet: func(v *command.Value, d *command.Data) {
+			d.Set(evalArg, command.StringListValue(append(d.Values[evalArg].StringList(), v.StringList()...)...))
+		},
+	})
+	argsFileFlag = command.StringFlag("args-file", 'F', &command.ArgOpt{
+		Completor: &command.Completor{
+			SuggestionFetcher: &command.FileFetcher{},
+		},
+		Transformer: command.FileTransformer(),
+	})
+	// withFlag can be provided multiple times to enable multiple minor modes.
+	withFlag = command.StringListFlag(withArg, 'w', 1, 0, &command.ArgOpt{
+		CustomSet: func(v *command.Value, d *command.Data) {
+			d.Set(withArg, command.StringListValue(append(d.Values[withArg].StringList(), v.StringList()...)...))
+		},
+	})
+
+	// conflictingFlags are pairs of flags that can't be used together.
+	conflictingFlags = [][2]command.Flag{
+		{newFileFlag, readOnlyFlag},
+		{touchFlag, readOnlyFlag},
+		{touchFlag, dryRunFlag},
+		{newFileFlag, forceOpenFlag},
+		{readOnlyFlag, writableFlag},
+		{diredFlag, marksFlag},
+		{diredFlag, blameFlag},
+	}
 )
 
 func CLI() *Emacs {
 	return &Emacs{}
 }
 
-type Emacs struct {
-	// Aliases is a map from alias to full file path.
-	Aliases map[string]map[string][]string
-	changed bool
-	Caches  map[string][]string
+type Emacs struct {
+	// Version is the version of the JSON format the object was saved with.
+	// Objects saved before versions were added are version 0.
+	Version int
+	// Aliases is a map from alias to full file path.
+	Aliases map[string]map[string][]string
+	changed bool
+	Caches  map[string][]string
+	// History is the list of previous invocations that opened files, ordered
+	// from oldest to newest.
+	History []*historyEntry
+
+	DaemonMode bool
+	// DirDaemonMode is a map from directory to whether or not daemon mode is
+	// used when running from that directory (or any directory below it).
+	// Directories without an entry use DaemonMode.
+	DirDaemonMode map[string]bool
+	// AutoDaemon is whether or not the daemon is used based on whether or
+	// not its server socket exists (rather than on DaemonMode).
+	AutoDaemon bool
+	// DaemonChdir is whether or not daemon files are opened with their own
+	// directory as the default-directory.
+	DaemonChdir bool
+	// Binary is the path to the emacs binary. If empty, then emacs is run
+	// from the PATH.
+	Binary string
+	// Root is the directory that relative file arguments are resolved
+	// against. If empty, then the current directory is used.
+	Root string
+	// Socket is the name of the daemon's server socket. If empty, then the
+	// default socket is used.
+	Socket string
+	// CaseSensitive is whether or not file completion is case sensitive.
+	CaseSensitive bool
+	// NormalOrder is whether or not basic mode opens files in the provided
+	// order (rather than in reverse so the first file is the active buffer).
+	NormalOrder bool
+	// GUI is whether or not files are opened in a graphical window (rather
+	// than in the terminal).
+	GUI bool
+	// ExtraFlags are additional flags passed to emacs in basic mode.
+	ExtraFlags []string
+	// HistoryLimit is the number of history entries to keep. If unset, then
+	// historyLimit is used.
+	HistoryLimit int
+	// MaxSize is the file size (in bytes) above which a warning is output
+	// when the file is opened. If unset, then no warning is output.
+	MaxSize int
+	// AliasUses is a map from alias group to the number of times each alias
+	// was used to open files.
+	AliasUses map[string]map[string]int
+	// NewFileAliases is a map from alias group to the aliases that were added
+	// with the new file flag. These aliases always open with that flag.
+	NewFileAliases map[string]map[string]bool
+	// CaseInsensitiveAliases is whether or not aliases are resolved
+	// regardless of case.
+	CaseInsensitiveAliases bool
+	// ExtensionLines is a map from file extension to the line that files
+	// with that extension are opened at when no position is provided. Values
+	// are either a line number or "end".
+	ExtensionLines map[string]string
+
+	// overlaid contains the aliases that were set from the overlay file.
+	overlaid map[string]map[string]*overlaidAlias
+	// fs is the filesystem used when opening files. If nil, then the OS
+	// filesystem is used.
+	fs fileSystem
+}
+
+type historyEntry struct {
+	// Files are the absolute paths of the files that were opened.
+	Files []string
+	// Args are the (transformed) arguments of the invocation, if it was run
+	// through the cached emacs command.
+	Args []string
+	// Time is when the files were opened. It is the zero time for entries
+	// that were recorded before timestamps were added.
+	Time time.Time
+}
+
+// replayArgs returns the arguments needed to re-run the history entry.
+func (he *historyEntry) replayArgs() []string {
+	if len(he.Args) > 0 {
+		return he.Args
+	}
+	return he.Files
+}
+
+type overlaidAlias struct {
+	overlay []string
+	primary []string
+	existed bool
+}
+
+func (e *Emacs) AliasMap() map[string]map[string][]string {
+	if e.Aliases == nil {
+		e.Aliases = map[string]map[string][]string{}
+	}
+	return e.Aliases
+}
+
+func (e *Emacs) Setup() []string { return nil }
+
+// fileSystem returns the filesystem used when opening files.
+func (e *Emacs) fileSystem() fileSystem {
+	if e.fs == nil {
+		return &osFileSystem{}
+	}
+	return e.fs
+}
+
+func (e *Emacs) MarkChanged() {
+	e.changed = true
+}
+
+// Name returns the name of the CLI.
+func (e *Emacs) Name() string {
+	return "e"
+}
+
+// Load creates an Emacs object from a JSON string.
+func (e *Emacs) Load(jsn string) error {
+	if jsn != "" {
+		if err := json.Unmarshal([]byte(jsn), e); err != nil {
+			return fmt.Errorf("failed to unmarshal emacs json: %v", err)
+		}
+		if err := e.migrate(); err != nil {
+			return err
+		}
+	}
+	return e.loadOverlay()
+}
+
+// migrations are the functions that convert the JSON format from one version
+// to the next. migrations[i] converts an object from version i to version i+1.
+var migrations = []func(e *Emacs){
+	migrateCacheToHistory,
+}
+
+// currentVersion is the version of the JSON format written by this package.
+var currentVersion = len(migrations)
+
+// migrate runs all of the migrations needed to bring the object up to the
+// current version.
+func (e *Emacs) migrate() error {
+	if e.Version > currentVersion {
+		return fmt.Errorf("emacs json version (%d) is newer than the supported version (%d)", e.Version, currentVersion)
+	}
+	for ; e.Version < currentVersion; e.Version++ {
+		migrations[e.Version](e)
+		e.MarkChanged()
+	}
+	return nil
+}
+
+// migrateCacheToHistory records the cached command in the history if
+// the object was saved before the history was added.
+func migrateCacheToHistory(e *Emacs) {
+	if len(e.History) > 0 {
+		return
+	}
+	if cached, ok := e.Caches[cacheName]; ok && len(cached) > 0 {
+		e.History = []*historyEntry{{Args: cached}}
+	}
+}
+
+// loadOverlay sets the aliases defined in the overlay file (if one is
+// configured). Overlay aliases take precedence over the primary aliases.
+func (e *Emacs) loadOverlay() error {
+	filename := getenv(overlayEnvVar)
+	if filename == "" {
+		return nil
+	}
+
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("failed to read alias overlay file: %v", err)
+	}
+	var overlay map[string]map[string][]string
+	if err := json.Unmarshal(b, &overlay); err != nil {
+		return fmt.Errorf("failed to unmarshal alias overlay json: %v", err)
+	}
+
+	e.overlaid = map[string]map[string]*overlaidAlias{}
+	for group, m := range overlay {
+		e.overlaid[group] = map[string]*overlaidAlias{}
+		if e.AliasMap()[group] == nil {
+			e.AliasMap()[group] = map[string][]string{}
+		}
+		for alias, v := range m {
+			primary, ok := e.AliasMap()[group][alias]
+			e.overlaid[group][alias] = &overlaidAlias{
+				overlay: v,
+				primary: primary,
+				existed: ok,
+			}
+			e.AliasMap()[group][alias] = v
+		}
+	}
+	return nil
+}
+
+// MarshalJSON marshals the Emacs object without any of the overlay aliases so
+// machine-specific aliases are never written back to the primary aliases.
+func (e *Emacs) MarshalJSON() ([]byte, error) {
+	type emacsJSON Emacs
+	cp := *e
+	cp.Version = currentVersion
+	if len(e.overlaid) > 0 {
+		cp.Aliases = map[string]map[string][]string{}
+		for group, m := range e.Aliases {
+			cp.Aliases[group] = map[string][]string{}
+			for alias, v := range m {
+				cp.Aliases[group][alias] = v
+			}
+		}
+
+		for group, m := range e.overlaid {
+			for alias, oa := range m {
+				// Keep the value if it was changed after the overlay was applied.
+				if v, ok := cp.Aliases[group][alias]; !ok || !sliceEquals(v, oa.overlay) {
+					continue
+				}
+				if oa.existed {
+					cp.Aliases[group][alias] = oa.primary
+				} else {
+					delete(cp.Aliases[group], alias)
+				}
+			}
+		}
+	}
+	return json.Marshal((*emacsJSON)(&cp))
+}
+
+func sliceEquals(this, that []string) bool {
+	if len(this) != len(that) {
+		return false
+	}
+	for i := range this {
+		if this[i] != that[i] {
+			return false
+		}
+	}
+	return true
+}
+
+type fileOpts struct {
+	name       string
+	lineNumber int
+	column     int
+	// symbol is the text to search for after opening the file.
+	symbol string
+	// end is whether or not to move to the end of the file.
+	end bool
+	// percent is how far through the file to move to (if set).
+	percent  *int
+	readOnly bool
+	// dired is whether or not the file is a directory to open in dired.
+	dired bool
+	// shellCommand is the shell command whose output is opened (instead of
+	// a file).
+	shellCommand string
+	// bookmark is the emacs bookmark to jump to (instead of opening a file).
+	bookmark string
+	// buffer is the existing buffer to switch to (instead of opening a
+	// file).
+	buffer string
+}
+
+// bufferArg returns whether or not the argument is opened in a buffer without
+// a file (a shell command or a bookmark) so it isn't a path.
+func bufferArg(s string) bool {
+	return strings.HasPrefix(s, shellCommandPrefix) || strings.HasPrefix(s, bookmarkPrefix)
+}
+
+// OpenEditor constructs an emacs command to open the specified files.
+func (e *Emacs) OpenEditor(input *command.Input, output command.Output, data *command.Data, eData *command.ExecuteData) error {
+	// Touched files are new files that are created right away.
+	touch := data.Values[touchFlag.Name()].Bool()
+	allowNewFiles := data.Values[newFileArg].Bool() || touch
+	ergs := data.Values[emacsArg].StringList()
+	dired := data.Values[diredFlag.Name()].Bool()
+	fs := e.fileSystem()
+
+	for _, cf := range conflictingFlags {
+		if data.Values[cf[0].Name()].Bool() && data.Values[cf[1].Name()].Bool() {
+			return output.Stderr("%q and %q flags can't be used together", cf[0].Name(), cf[1].Name())
+		}
+	}
+
+	// If only a directory was provided, then just cd into the directory.
+	// Symlinks are resolved first so links to directories (with or without
+	// a trailing slash) are treated the same as the directory itself.
+	if len(ergs) == 1 && !dired {
+		dir := filepath.Clean(ergs[0])
+		target := dir
+		if r, err := fs.EvalSymlinks(dir); err == nil {
+			target = r
+		}
+		fi, _ := fs.Stat(target)
+		if fi != nil && fi.IsDir() {
+			if data.Values[evalArg].Provided() {
+				return output.Stderr("%q flag can't be used when no files are opened", evalArg)
+			}
+			cmd := fmt.Sprintf("cd %s", dir)
+			if data.Values[dryRunFlag.Name()].Bool() {
+				output.Stdout("%s", cmd)
+				return nil
+			}
+			eData.Executable = append(eData.Executable, cmd)
+			return nil
+		}
+	}
+
+	files := make([]*fileOpts, 0, len(ergs))
+	il := data.Values[lineArg].IntList()
+	cl := data.Values[columnArg].IntList()
+	syms := data.Values[symbolArg].StringList()
+	el := data.Values[endArg].IntList()
+	pl := data.Values[percentArg].StringList()
+	for i, erg := range ergs {
+		if strings.HasPrefix(erg, bookmarkPrefix) {
+			files = append(files, &fileOpts{
+				name:     erg,
+				bookmark: strings.TrimPrefix(erg, bookmarkPrefix),
+			})
+			continue
+		}
+
+		if erg == stdinFileList {
+			sfs, err := readFileList(stdin)
+			if err != nil {
+				return output.Err(err)
+			}
+			files = append(files, sfs...)
+			continue
+		}
+
+		if strings.HasPrefix(erg, listFilePrefix) {
+			lfs, err := readListFile(fs, strings.TrimPrefix(erg, listFilePrefix))
+			if err != nil {
+				return output.Err(err)
+			}
+			files = append(files, lfs...)
+			continue
+		}
+
+		if strings.HasPrefix(erg, shellCommandPrefix) {
+			files = append(files, &fileOpts{
+				name:         erg,
+				shellCommand: strings.TrimPrefix(erg, shellCommandPrefix),
+			})
+			continue
+		}
+
+		var iv, cv int
+		var sym string
+		var end bool
+		var pct *int
+		if i < len(il) {
+			iv = il[i]
+		}
+		if i < len(cl) {
+			cv = cl[i]
+		}
+		if i < len(syms) {
+			sym = syms[i]
+		}
+		if i < len(el) {
+			end = el[i] != 0
+		}
+		if i < len(pl) && pl[i] != "" {
+			p, _ := strconv.Atoi(pl[i])
+			pct = &p
+		}
+
+		names := []string{erg}
+		if strings.ContainsAny(erg, globChars) && !trampRegex.MatchString(erg) {
+			matches, err := fs.Glob(erg)
+			if err != nil {
+				return output.Stderr("invalid glob pattern %q: %v", erg, err)
+			}
+			if len(matches) == 0 {
+				return output.Stderr("no files match glob pattern %q", erg)
+			}
+			names = matches
+		}
+		for _, name := range names {
+			fo := &fileOpts{
+				name:       name,
+				lineNumber: iv,
+				column:     cv,
+				symbol:     sym,
+				end:        end,
+				percent:    pct,
+			}
+			if iv == 0 && cv == 0 && sym == "" && !end && pct == nil {
+				e.extensionPosition(fo)
+			}
+			files = append(files, fo)
+		}
+	}
+
+	// Open the directory of each file (or the directory itself) in dired.
+	if dired {
+		for i, f := range files {
+			if f.shellCommand != "" || f.bookmark != "" {
+				continue
+			}
+			dir := filepath.Clean(f.name)
+			if fi, err := fs.Stat(dir); err != nil || !fi.IsDir() {
+				dir = filepath.Dir(dir)
+			}
+			files[i] = &fileOpts{name: dir, dired: true}
+		}
+	}
+
+	// The same file can be provided more than once (e.g. by an alias and by
+	// its path), so only open the first occurrence.
+	seen := map[string]bool{}
+	var distinct []*fileOpts
+	for _, f := range files {
+		if !seen[f.name] {
+			seen[f.name] = true
+			distinct = append(distinct, f)
+		}
+	}
+	files = distinct
+
+	readOnly := data.Values[readOnlyFlag.Name()].Bool()
+	for _, f := range files {
+		f.readOnly = readOnly
+
+		// Remote files can't be checked locally (and shell commands and
+		// bookmarks aren't files).
+		if trampRegex.MatchString(f.name) || f.shellCommand != "" || f.bookmark != "" {
+			continue
+		}
+
+		// Check file exists, unless --new (or --force-open) flag provided.
+		fi, err := fs.Stat(f.name)
+		if !allowNewFiles && !data.Values[forceOpenFlag.Name()].Bool() && os.IsNotExist(err) {
+			return output.Stderr("file %q does not exist; include %q flag to create it", f.name, newFileArg)
+		}
+
+		if e.MaxSize > 0 && fi != nil && fi.Size() > int64(e.MaxSize) && !data.Values[largeFlag.Name()].Bool() {
+			output.Stderr("file %q is larger than %d bytes; include %q flag to open it in fundamental mode", f.name, e.MaxSize, largeFlag.Name())
+		}
+
+		// Create any missing parent directories for new files (dry runs
+		// don't create anything).
+		if allowNewFiles && os.IsNotExist(err) && !data.Values[dryRunFlag.Name()].Bool() {
+			dir := filepath.Dir(f.name)
+			if _, err := fs.Stat(dir); os.IsNotExist(err) {
+				if err := fs.MkdirAll(dir, 0777); err != nil {
+					return output.Stderr("failed to create directory %q: %v", dir, err)
+				}
+			}
+			// Aliases need the file to exist (the alias arg is only set when
+			// adding an alias).
+			if touch || data.Values[aliasArg].Provided() {
+				if err := fs.Create(f.name); err != nil {
+					return output.Stderr("failed to create file %q: %v", f.name, err)
+				}
+			}
+		}
+
+		// Files are opened as root so local permissions don't matter.
+		if data.Values[sudoFlag.Name()].Bool() {
+			f.name = sudoPrefix + f.name
+			continue
+		}
+
+		if !f.readOnly && fi != nil && !writable(fi) && !data.Values[writableFlag.Name()].Bool() {
+			output.Stderr("file %q is not writable so opening it read-only (sudo is needed to edit it); include %q flag to open it normally", f.name, writableFlag.Name())
+			f.readOnly = true
+		}
+	}
+
+	return e.openFiles(output, data, eData, files)
+}
+
+// extensionPosition sets the file's position to the configured default for
+// its extension (if there is one).
+func (e *Emacs) extensionPosition(fo *fileOpts) {
+	switch v := e.ExtensionLines[filepath.Ext(fo.name)]; v {
+	case "":
+	case "end":
+		fo.end = true
+	default:
+		fo.lineNumber, _ = strconv.Atoi(v)
+	}
+}
+
+// bookmarkFile returns the path to the emacs bookmark file (or the file in the
+// EMACS_BOOKMARKS environment variable, if set).
+func bookmarkFile() (string, error) {
+	if f := getenv("EMACS_BOOKMARKS"); f != "" {
+		return f, nil
+	}
+	home, err := userHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %v", err)
+	}
+	return filepath.Join(home, ".emacs.d", "bookmarks"), nil
+}
+
+// bookmarkElisp returns the elisp that sets a bookmark at the provided line
+// of the current buffer. Bookmarks are named FILE:LINE (using the file's base
+// name).
+func bookmarkElisp(name string, line int) string {
+	return fmt.Sprintf("(save-excursion (goto-char (point-min)) (forward-line %d) (bookmark-set %q))", line-1, fmt.Sprintf("%s:%d", filepath.Base(name), line))
+}
+
+// readListFile reads the files (and optional line numbers) listed in the
+// provided file. Each line is either `path`, `path:line`, or `path<TAB>line`.
+func readListFile(fs fileSystem, filename string) ([]*fileOpts, error) {
+	f, err := fs.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open list file: %v", err)
+	}
+	defer f.Close()
+	return readFileList(f)
+}
+
+// readFileList parses a newline-separated list of files (see readListFile).
+// Blank lines and lines starting with "#" are ignored.
+func readFileList(r io.Reader) ([]*fileOpts, error) {
+	var files []*fileOpts
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fo, err := parseFileLine(line)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, fo)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read file list: %v", err)
+	}
+	return files, nil
+}
+
+// parseFileLine parses a `path`, `path:line`, or `path<TAB>line` string.
+// Only a trailing `:digits` is treated as a line number so paths that
+// contain colons are left intact.
+func parseFileLine(s string) (*fileOpts, error) {
+	name, line := s, ""
+	if i := strings.LastIndex(s, "\t"); i >= 0 {
+		name, line = strings.TrimSpace(s[:i]), strings.TrimSpace(s[i+1:])
+	} else if i := strings.LastIndex(s, ":"); i >= 0 {
+		if _, err := strconv.Atoi(s[i+1:]); err == nil {
+			name, line = s[:i], s[i+1:]
+		}
+	}
+
+	fo := &fileOpts{}
+	if line != "" {
+		ln, err := strconv.Atoi(line)
+		if err != nil {
+			return nil, fmt.Errorf("invalid line number in %q", s)
+		}
+		fo.lineNumber = ln
+	}
+
+	absName, err := filepath.Abs(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absolute path for %q: %v", name, err)
+	}
+	fo.name = absName
+	return fo, nil
+}
+
+// editorOpts returns the editor options configured by the user.
+func (e *Emacs) editorOpts() *editorOpts {
+	return &editorOpts{
+		chdir:       e.DaemonChdir,
+		binary:      e.Binary,
+		socket:      e.Socket,
+		normalOrder: e.NormalOrder,
+		gui:         e.GUI,
+		extraFlags:  e.ExtraFlags,
+	}
+}
+
+// daemonRunning returns whether or not the daemon's server socket exists.
+func (e *Emacs) daemonRunning() bool {
+	socket := e.Socket
+	if socket == "" {
+		socket = "server"
+	}
+	if !filepath.IsAbs(socket) {
+		dir := fmt.Sprintf("/tmp/emacs%d", getuid())
+		if xdg := getenv("XDG_RUNTIME_DIR"); xdg != "" {
+			dir = filepath.Join(xdg, "emacs")
+		}
+		socket = filepath.Join(dir, socket)
+	}
+	_, err := e.fileSystem().Stat(socket)
+	return err == nil
+}
+
+// openFiles appends the command that opens all of the provided files.
+func (e *Emacs) openFiles(output command.Output, data *command.Data, eData *command.ExecuteData, files []*fileOpts) error {
+	daemonMode := e.DaemonMode
+	if wd, err := getwd(); err == nil {
+		daemonMode = e.dirDaemonMode(wd)
+	}
+	if e.AutoDaemon {
+		daemonMode = e.daemonRunning()
+	}
+	getCmd := basic
+	if daemonMode {
+		getCmd = daemon
+	}
+
+	// A new emacs process can only jump to bookmarks that are saved in the
+	// bookmark file.
+	if !daemonMode && !data.Values[aliasArg].Provided() {
+		for _, f := range files {
+			if f.bookmark == "" {
+				continue
+			}
+			bf, err := bookmarkFile()
+			if err != nil {
+				return output.Err(err)
+			}
+			if _, err := e.fileSystem().Stat(bf); err != nil {
+				return output.Stderr("can't jump to bookmark %q in basic mode since the bookmark file %q doesn't exist", f.bookmark, bf)
+			}
+			break
+		}
+	}
+
+	eo := e.editorOpts()
+	eo.debugInit = data.Values[debugInitFlag.Name()].Bool()
+	eo.evals = data.Values[evalArg].StringList()
+	if data.Values[largeFlag.Name()].Bool() {
+		eo.evals = append([]string{"(fundamental-mode)"}, eo.evals...)
+	}
+	if data.Values[blameFlag.Name()].Bool() {
+		if len(files) == 0 || files[0].lineNumber == 0 {
+			output.Stderr("ignoring %q flag since no line number was provided", blameFlag.Name())
+		} else {
+			eo.evals = append(eo.evals, blameElisp)
+		}
+	}
+	if data.Values[marksFlag.Name()].Bool() {
+		if len(files) != 1 {
+			return output.Stderr("%q flag can only be used with a single file", marksFlag.Name())
+		}
+		var lines []int
+		if files[0].lineNumber != 0 {
+			lines = append(lines, files[0].lineNumber)
+		}
+		lines = append(lines, data.Values[marksArg].IntList()...)
+		if len(lines) == 0 {
+			output.Stderr("ignoring %q flag since no line number was provided", marksFlag.Name())
+		}
+		for _, l := range lines {
+			eo.evals = append(eo.evals, bookmarkElisp(files[0].name, l))
+		}
+	}
+	if data.Values[passthroughArg].Provided() {
+		if daemonMode {
+			output.Stderr(`ignoring arguments after "--" since they are only passed to emacs in basic mode`)
+		} else {
+			eo.passthrough = data.Values[passthroughArg].StringList()
+		}
+	}
+	for _, m := range data.Values[withArg].StringList() {
+		if !minorModeRegex.MatchString(m) {
+			return output.Stderr("invalid minor mode %q", m)
+		}
+		eo.minorModes = append(eo.minorModes, m)
+	}
+	if data.Values[newFrameFlag.Name()].Bool() {
+		if !daemonMode {
+			output.Stderr("ignoring %q flag since new frames are only created in daemon mode", newFrameFlag.Name())
+		} else {
+			eo.gui = true
+		}
+	}
+	if data.Values[noWaitFlag.Name()].Bool() {
+		if !daemonMode {
+			output.Stderr("ignoring %q flag since only the daemon client can return immediately", noWaitFlag.Name())
+		} else {
+			eo.noWait = true
+		}
+	}
+	if v, ok := data.Values[monitorFlag.Name()]; ok {
+		if !eo.gui {
+			output.Stderr("ignoring %q flag since frames are only positioned in GUI mode", monitorFlag.Name())
+		} else {
+			m := v.Int()
+			eo.monitor = &m
+		}
+	}
+
+	gotCmd, err := getCmd(eo, files...)
+	if err != nil {
+		return output.Err(err)
+	}
+
+	if data.Values[verboseFlag.Name()].Bool() {
+		if len(files) == 1 {
+			output.Stdout("Opening 1 file")
+		} else {
+			output.Stdout("Opening %d files", len(files))
+		}
+	}
+
+	if data.Values[dryRunFlag.Name()].Bool() {
+		output.Stdout("%s", gotCmd)
+		return nil
+	}
+
+	eData.Executable = append(eData.Executable, gotCmd)
+	// History is updated in the executor so that nothing is recorded when
+	// the files are only being processed (e.g. when adding an alias).
+	if !data.Values[noHistoryFlag.Name()].Bool() {
+		addExecutor(eData, func(command.Output, *command.Data) error {
+			e.addHistory(files)
+			return nil
+		})
+	}
+
+	if data.Values[copyPathFlag.Name()].Bool() {
+		var names []string
+		for _, f := range files {
+			names = append(names, f.name)
+		}
+		addExecutor(eData, func(output command.Output, _ *command.Data) error {
+			if err := clip.Copy(strings.Join(names, "\n")); err != nil {
+				return output.Stderr("failed to copy path to clipboard: %v", err)
+			}
+			return nil
+		})
+	}
+	return nil
+}
+
+// addHistory records the opened files in the history.
+func (e *Emacs) addHistory(files []*fileOpts) {
+	he := &historyEntry{Time: now()}
+	for _, f := range files {
+		he.Files = append(he.Files, f.name)
+	}
+	e.History = append(e.History, he)
+	e.trimHistory()
+	e.MarkChanged()
+}
+
+// trimHistory removes the oldest history entries that exceed the limit.
+func (e *Emacs) trimHistory() {
+	limit := historyLimit
+	if e.HistoryLimit > 0 {
+		limit = e.HistoryLimit
+	}
+	if len(e.History) > limit {
+		e.History = e.History[len(e.History)-limit:]
+	}
+}
+
+// SetHistoryLimit sets the number of history entries to keep.
+func (e *Emacs) SetHistoryLimit(output command.Output, data *command.Data) error {
+	e.HistoryLimit = data.Values[limitArg].Int()
+	e.trimHistory()
+	e.MarkChanged()
+	output.Stdout("History limit set to %d.", e.HistoryLimit)
+	return nil
+}
+
+// SetExtraFlags adds extra flags that are passed to emacs in basic mode. If no
+// flags are provided, then the current extra flags are listed.
+func (e *Emacs) SetExtraFlags(output command.Output, data *command.Data) error {
+	if data.Values[clearFlag.Name()].Bool() {
+		e.ExtraFlags = nil
+		e.MarkChanged()
+		output.Stdout("Extra flags cleared.")
+		return nil
+	}
+
+	flags := data.Values[extraFlagsArg].StringList()
+	if len(flags) == 0 {
+		for _, f := range e.ExtraFlags {
+			output.Stdout("%s", f)
+		}
+		return nil
+	}
+	e.ExtraFlags = append(e.ExtraFlags, flags...)
+	e.MarkChanged()
+	output.Stdout("Extra flags: %s", strings.Join(e.ExtraFlags, " "))
+	return nil
+}
+
+// ClearCache removes the cached command so that running with no arguments
+// no longer re-runs the last command.
+func (e *Emacs) ClearCache(output command.Output, data *command.Data) error {
+	delete(e.Caches, cacheName)
+	e.MarkChanged()
+	output.Stdout("Cache cleared.")
+	return nil
+}
+
+// dirDaemonMode returns whether or not daemon mode is used in the provided
+// directory. The setting of the closest directory (or parent directory) is
+// used, otherwise the global setting is used.
+func (e *Emacs) dirDaemonMode(dir string) bool {
+	for {
+		if dm, ok := e.DirDaemonMode[dir]; ok {
+			return dm
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return e.DaemonMode
+		}
+		dir = parent
+	}
+}
+
+// ToggleDaemonMode toggles daemon mode for the current directory (or
+// globally if the global flag is provided).
+func (e *Emacs) ToggleDaemonMode(output command.Output, data *command.Data) error {
+	state := "deactivated"
+	if data.Values[globalFlag.Name()].Bool() {
+		e.DaemonMode = !e.DaemonMode
+		e.MarkChanged()
+		if e.DaemonMode {
+			state = "activated"
+		}
+		output.Stdout("Daemon mode %s.", state)
+		return nil
+	}
+
+	wd, err := getwd()
+	if err != nil {
+		return output.Stderr("failed to get current directory: %v", err)
+	}
+	dm := !e.dirDaemonMode(wd)
+	if e.DirDaemonMode == nil {
+		e.DirDaemonMode = map[string]bool{}
+	}
+	e.DirDaemonMode[wd] = dm
+	e.MarkChanged()
+	if dm {
+		state = "activated"
+	}
+	output.Stdout("Daemon mode %s in %s.", state, wd)
+	return nil
+}
+
+// StartDaemon starts the emacs daemon (unless it is already running).
+func (e *Emacs) StartDaemon(input *command.Input, output command.Output, data *command.Data, eData *command.ExecuteData) error {
+	eo := e.editorOpts()
+	// emacsclient fails if the daemon isn't running.
+	if _, err := run([]string{fmt.Sprintf("%s -e '(server-running-p)'", eo.client())}); err == nil {
+		output.Stdout("Daemon already running.")
+		return nil
+	}
+	eData.Executable = append(eData.Executable,
+		"echo Starting emacs daemon",
+		fmt.Sprintf("%s %s", eo.emacs(), eo.daemonFlag()),
+		"echo Success!",
+	)
+	return nil
+}
+
+// CompletionScript outputs the script that sets up shell completion for
+// the CLI.
+func (e *Emacs) CompletionScript(output command.Output, data *command.Data) error {
+	script := bashCompletion
+	if data.Values[shellArg].String() == "zsh" {
+		script = zshCompletion
+	}
+	output.Stdout(script, e.Name())
+	return nil
+}
+
+// ShowCache prints the cached command that is re-run when no arguments are
+// provided.
+func (e *Emacs) ShowCache(output command.Output, data *command.Data) error {
+	sl, ok := e.Caches[cacheName]
+	if !ok {
+		return output.Stderr("no cached command")
+	}
+	output.Stdout("%s", strings.Join(sl, " "))
+	return nil
+}
+
+// SetBinary sets the emacs binary to use. If no binary is provided, then the
+// default emacs in the PATH is used.
+func (e *Emacs) SetBinary(output command.Output, data *command.Data) error {
+	if !data.Values[binaryArg].Provided() {
+		e.Binary = ""
+		e.MarkChanged()
+		output.Stdout("Emacs binary reset to default.")
+		return nil
+	}
+
+	b := data.Values[binaryArg].String()
+	fi, err := e.fileSystem().Stat(b)
+	if err != nil {
+		return output.Stderr("failed to find emacs binary: %v", err)
+	}
+	if fi.IsDir() {
+		return output.Stderr("%q is a directory", b)
+	}
+	e.Binary = b
+	e.MarkChanged()
+	output.Stdout("Emacs binary set to %s.", b)
+	return nil
+}
+
+// SetSocket sets the name of the daemon's server socket. If no name is
+// provided, then the default socket is used.
+func (e *Emacs) SetSocket(output command.Output, data *command.Data) error {
+	e.Socket = data.Values[socketArg].String()
+	e.MarkChanged()
+	if e.Socket == "" {
+		output.Stdout("Daemon socket reset to default.")
+	} else {
+		output.Stdout("Daemon socket set to %s.", e.Socket)
+	}
+	return nil
+}
+
+// SetMaxSize sets the file size above which a warning is output when opening
+// a file. If no size is provided, then no warnings are output.
+func (e *Emacs) SetMaxSize(output command.Output, data *command.Data) error {
+	e.MaxSize = data.Values[maxSizeArg].Int()
+	e.MarkChanged()
+	if e.MaxSize == 0 {
+		output.Stdout("Max file size unset.")
+	} else {
+		output.Stdout("Max file size set to %d bytes.", e.MaxSize)
+	}
+	return nil
+}
+
+// SetExtensionLine sets the line that files with the provided extension are
+// opened at. If no line is provided, then the extension's line is removed.
+// If no extension is provided, then all of the extension lines are listed.
+func (e *Emacs) SetExtensionLine(output command.Output, data *command.Data) error {
+	if !data.Values[extensionArg].Provided() {
+		var exts []string
+		for ext := range e.ExtensionLines {
+			exts = append(exts, ext)
+		}
+		sort.Strings(exts)
+		for _, ext := range exts {
+			output.Stdout("%s: %s", ext, e.ExtensionLines[ext])
+		}
+		return nil
+	}
+
+	ext := data.Values[extensionArg].String()
+	if !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	if !data.Values[extLineArg].Provided() {
+		if _, ok := e.ExtensionLines[ext]; ok {
+			delete(e.ExtensionLines, ext)
+			e.MarkChanged()
+		}
+		output.Stdout("Default line for %s files unset.", ext)
+		return nil
+	}
+
+	if e.ExtensionLines == nil {
+		e.ExtensionLines = map[string]string{}
+	}
+	e.ExtensionLines[ext] = data.Values[extLineArg].String()
+	e.MarkChanged()
+	output.Stdout("Default line for %s files set to %s.", ext, e.ExtensionLines[ext])
+	return nil
+}
+
+// SetRoot sets the directory that relative file arguments are resolved
+// against. If no directory is provided, then the current directory is used.
+func (e *Emacs) SetRoot(output command.Output, data *command.Data) error {
+	if !data.Values[rootArg].Provided() {
+		e.Root = ""
+		e.MarkChanged()
+		output.Stdout("Project root reset to current directory.")
+		return nil
+	}
+
+	root := data.Values[rootArg].String()
+	fi, err := e.fileSystem().Stat(root)
+	if err != nil {
+		return output.Stderr("failed to find project root: %v", err)
+	}
+	if !fi.IsDir() {
+		return output.Stderr("%q is not a directory", root)
+	}
+	e.Root = root
+	e.MarkChanged()
+	output.Stdout("Project root set to %s.", root)
+	return nil
+}
+
+// Historical lists previous invocations or re-runs the invocation at the
+// provided index.
+func (e *Emacs) Historical(input *command.Input, output command.Output, data *command.Data, eData *command.ExecuteData) error {
+	if !data.Values[historicalArg].Provided() {
+		for i := len(e.History) - 1; i >= 0; i-- {
+			he := e.History[i]
+			if he.Time.IsZero() {
+				output.Stdout("%d: %s", i, strings.Join(he.replayArgs(), " "))
+			} else {
+				output.Stdout("%d: [%s] %s", i, he.Time.Format(historyTimeFormat), strings.Join(he.replayArgs(), " "))
+			}
+		}
+		return nil
+	}
+
+	idx := data.Values[historicalArg].Int()
+	if idx >= len(e.History) {
+		return output.Stderr("only %d history entries exist", len(e.History))
+	}
+	return e.replay(e.History[idx], input, output, data, eData)
+}
+
+// Repeat re-runs the command from the provided number of invocations ago
+// (so 1 is the most recent command).
+func (e *Emacs) Repeat(input *command.Input, output command.Output, data *command.Data, eData *command.ExecuteData) error {
+	offset := data.Values[repeatArg].Int()
+	if offset > len(e.History) {
+		return output.Stderr("only %d history entries exist", len(e.History))
+	}
+	return e.replay(e.History[len(e.History)-offset], input, output, data, eData)
+}
+
+// replay re-runs the provided history entry.
+func (e *Emacs) replay(he *historyEntry, input *command.Input, output command.Output, data *command.Data, eData *command.ExecuteData) error {
+	input.PushFront(he.replayArgs()...)
+	return executeNodes(e.historyNode(e.emacsArgNode(fileAliaserName)), input, output, data, eData)
+}
+
+// Scratch opens the scratch buffer.
+func (e *Emacs) Scratch(input *command.Input, output command.Output, data *command.Data, eData *command.ExecuteData) error {
+	// The scratch buffer isn't a file so it isn't recorded in the history.
+	data.Set(noHistoryFlag.Name(), command.BoolValue(true))
+	return e.openFiles(output, data, eData, []*fileOpts{{name: scratchBuffer, buffer: scratchBuffer}})
+}
+
+// InitFile opens the emacs init file (or the file in the EMACS_INIT
+// environment variable, if set).
+func (e *Emacs) InitFile(input *command.Input, output command.Output, data *command.Data, eData *command.ExecuteData) error {
+	f := getenv("EMACS_INIT")
+	if f == "" {
+		home, err := userHomeDir()
+		if err != nil {
+			return output.Stderr("failed to get home directory: %v", err)
+		}
+		f = filepath.Join(home, ".emacs.d", "init.el")
+	}
+	input.PushFront(f)
+	return executeNodes(e.historyNode(e.emacsArgNode(fileAliaserName)), input, output, data, eData)
+}
+
+// FindFile opens the most recently opened file that matches the provided
+// regexp.
+func (e *Emacs) FindFile(input *command.Input, output command.Output, data *command.Data, eData *command.ExecuteData) error {
+	rx, err := regexp.Compile(data.Values[regexpArg].String())
+	if err != nil {
+		return output.Stderr("Invalid regexp: %v", err)
+	}
+	for _, f := range e.recentFiles() {
+		if rx.MatchString(f) {
+			input.PushFront(f)
+			return executeNodes(e.historyNode(e.emacsArgNode(fileAliaserName)), input, output, data, eData)
+		}
+	}
+	return output.Stderr("no recent files match %q", rx.String())
+}
+
+// FileHistory prints all history entries that opened the provided file,
+// starting with the most recent.
+func (e *Emacs) FileHistory(output command.Output, data *command.Data) error {
+	f := data.Values[histFileArg].String()
+	for i := len(e.History) - 1; i >= 0; i-- {
+		he := e.History[i]
+		for _, hf := range he.Files {
+			if hf != f {
+				continue
+			}
+			if he.Time.IsZero() {
+				output.Stdout("%d: %s", i, strings.Join(he.Files, " "))
+			} else {
+				output.Stdout("%d: [%s] %s", i, he.Time.Format(historyTimeFormat), strings.Join(he.Files, " "))
+			}
+			break
+		}
+	}
+	return nil
+}
+
+// recentFiles returns the distinct files in the history, starting with the
+// most recently opened file.
+func (e *Emacs) recentFiles() []string {
+	var r []string
+	seen := map[string]bool{}
+	for i := len(e.History) - 1; i >= 0; i-- {
+		for _, f := range e.History[i].Files {
+			if !seen[f] {
+				seen[f] = true
+				r = append(r, f)
+			}
+		}
+	}
+	return r
+}
+
+// Recent lists the recently opened files if no index is provided, otherwise
+// it opens the recent file at the provided (1-based) index.
+func (e *Emacs) Recent(input *command.Input, output command.Output, data *command.Data, eData *command.ExecuteData) error {
+	rfs := e.recentFiles()
+	if !data.Values[recentArg].Provided() {
+		for i, f := range rfs {
+			output.Stdout("%d: %s", i+1, f)
+		}
+		return nil
+	}
+
+	idx := data.Values[recentArg].Int()
+	if idx > len(rfs) {
+		return output.Stderr("only %d recent files exist", len(rfs))
+	}
+	f := rfs[idx-1]
+	if _, err := e.fileSystem().Stat(f); os.IsNotExist(err) {
+		return output.Stderr("file %q does not exist", f)
+	}
+	return e.openFiles(output, data, eData, []*fileOpts{{name: f}})
+}
+
+// aliasCompletor completes alias names.
+func (e *Emacs) aliasCompletor() *command.Completor {
+	return &command.Completor{
+		Distinct: true,
+		SuggestionFetcher: command.SimpleFetcher(func(*command.Value, *command.Data) *command.Completion {
+			var s []string
+			for k := range e.Aliases[fileAliaserName] {
+				s = append(s, k)
+			}
+			return &command.Completion{
+				Suggestions: s,
+			}
+		}),
+	}
+}
+
+// completedGroupFlag returns the group flag with completion for the existing
+// alias groups.
+func (e *Emacs) completedGroupFlag() command.Flag {
+	return command.StringFlag(groupFlag.Name(), groupFlag.ShortName(), &command.ArgOpt{
+		Completor: e.groupCompletor(),
+	})
+}
+
+// groupCompletor completes alias group names.
+func (e *Emacs) groupCompletor() *command.Completor {
+	return &command.Completor{
+		SuggestionFetcher: command.SimpleFetcher(func(*command.Value, *command.Data) *command.Completion {
+			var s []string
+			for k := range e.Aliases {
+				s = append(s, k)
+			}
+			return &command.Completion{
+				Suggestions: s,
+			}
+		}),
+	}
+}
+
+// OpenGroup opens the files of every alias in the provided group.
+func (e *Emacs) OpenGroup(input *command.Input, output command.Output, data *command.Data, eData *command.ExecuteData) error {
+	group := data.Values[openGroupArg].String()
+	if len(e.Aliases[group]) == 0 {
+		return output.Stderr("alias group %q does not exist", group)
+	}
+
+	var aliases []string
+	for alias := range e.Aliases[group] {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+
+	// The alias values are set the same way as if they were provided as
+	// arguments so OpenEditor de-duplicates and checks the files.
+	for _, alias := range aliases {
+		for _, v := range e.Aliases[group][alias] {
+			if len(data.Values[emacsArg].StringList()) == 0 || !isPosition(v) {
+				fs := []string{v}
+				// Values that reference other aliases are expanded.
+				if e.isAlias(group, v) {
+					var err error
+					if fs, err = e.aliasRefFiles(group, v, nil); err != nil {
+						return output.Err(err)
+					}
+				}
+				data.Set(emacsArg, command.StringListValue(append(data.Values[emacsArg].StringList(), fs...)...))
+				continue
+			}
+			if n, err := strconv.Atoi(v); err == nil {
+				setPosition(data, lineArg, n)
+			} else if v == "$" {
+				setPosition(data, endArg, 1)
+			} else if m := lineColRegex.FindStringSubmatch(v); m != nil {
+				line, _ := strconv.Atoi(m[1])
+				col, _ := strconv.Atoi(m[2])
+				setPosition(data, lineArg, line)
+				setPosition(data, columnArg, col)
+			} else if m := symbolRegex.FindStringSubmatch(v); m != nil {
+				setStringPosition(data, symbolArg, m[1])
+			} else if m := percentRegex.FindStringSubmatch(v); m != nil {
+				setStringPosition(data, percentArg, m[1])
+			}
+		}
+	}
+	return e.OpenEditor(input, output, data, eData)
+}
+
+// aliasFiles returns the files in the alias values. Any stored line (and
+// column) numbers are attached to the preceding file (e.g. "file.go:42").
+func aliasFiles(values []string) []string {
+	var r []string
+	for _, v := range values {
+		if len(r) > 0 {
+			if isPosition(v) {
+				r[len(r)-1] = fmt.Sprintf("%s:%s", r[len(r)-1], v)
+				continue
+			}
+		}
+		r = append(r, v)
+	}
+	return r
+}
+
+// AliasDiff prints the files that are only in the first alias, only in the
+// second alias, and shared by both aliases.
+func (e *Emacs) AliasDiff(output command.Output, data *command.Data) error {
+	aliases := data.Values[aliasArg].StringList()
+	var sets []map[string]bool
+	for _, a := range aliases {
+		v, ok := e.Aliases[fileAliaserName][a]
+		if !ok {
+			return output.Stderr("Alias %q does not exist", a)
+		}
+		m := map[string]bool{}
+		for _, f := range aliasFiles(v) {
+			m[f] = true
+		}
+		sets = append(sets, m)
+	}
+
+	var onlyA, onlyB, shared []string
+	for f := range sets[0] {
+		if sets[1][f] {
+			shared = append(shared, f)
+		} else {
+			onlyA = append(onlyA, f)
+		}
+	}
+	for f := range sets[1] {
+		if !sets[0][f] {
+			onlyB = append(onlyB, f)
+		}
+	}
+	sort.Strings(onlyA)
+	sort.Strings(onlyB)
+	sort.Strings(shared)
+
+	output.Stdout("Only in %s: %s", aliases[0], strings.Join(onlyA, " "))
+	output.Stdout("Only in %s: %s", aliases[1], strings.Join(onlyB, " "))
+	output.Stdout("Shared: %s", strings.Join(shared, " "))
+	return nil
+}
+
+// ExportAliases outputs all aliases as JSON.
+func (e *Emacs) ExportAliases(output command.Output, data *command.Data) error {
+	b, err := json.MarshalIndent(e.Aliases, "", "  ")
+	if err != nil {
+		return output.Stderr("failed to marshal aliases: %v", err)
+	}
+	output.Stdout("%s", string(b))
+	return nil
+}
+
+// ImportAliases merges the aliases in the JSON read from stdin into the
+// existing aliases. Existing aliases are only overwritten if the force flag
+// is provided.
+func (e *Emacs) ImportAliases(output command.Output, data *command.Data) error {
+	b, err := ioutil.ReadAll(stdin)
+	if err != nil {
+		return output.Stderr("failed to read aliases: %v", err)
+	}
+	var aliases map[string]map[string][]string
+	if err := json.Unmarshal(b, &aliases); err != nil {
+		return output.Stderr("failed to unmarshal aliases: %v", err)
+	}
+
+	var groups []string
+	for group := range aliases {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+
+	// Validate everything before making any changes.
+	force := data.Values[forceFlag.Name()].Bool()
+	for _, group := range groups {
+		var names []string
+		for alias := range aliases[group] {
+			names = append(names, alias)
+		}
+		sort.Strings(names)
+		for _, alias := range names {
+			v := aliases[group][alias]
+			for _, f := range v {
+				if isPosition(f) {
+					continue
+				}
+				if !filepath.IsAbs(f) && !trampRegex.MatchString(f) && !bufferArg(f) {
+					return output.Stderr("alias %q references non-absolute path %q", alias, f)
+				}
+			}
+			if prev, ok := e.Aliases[group][alias]; ok && !force && !sliceEquals(prev, v) {
+				return output.Stderr("alias %q already exists in group %q; include %q flag to overwrite it", alias, group, forceFlag.Name())
+			}
+		}
+	}
+
+	before := copyAliases(e.Aliases)
+	for _, group := range groups {
+		if e.Aliases == nil {
+			e.Aliases = map[string]map[string][]string{}
+		}
+		if e.Aliases[group] == nil {
+			e.Aliases[group] = map[string][]string{}
+		}
+		for alias, v := range aliases[group] {
+			e.Aliases[group][alias] = v
+		}
+	}
+	e.MarkChanged()
+	e.aliasChanges(output, before, data.Values[quietFlag.Name()].Bool())
+	return nil
+}
+
+// PruneAliases deletes all aliases whose files no longer exist (or, if the
+// unused flag is provided, all aliases that were never used).
+func (e *Emacs) PruneAliases(output command.Output, data *command.Data) error {
+	dryRun := data.Values[dryRunFlag.Name()].Bool()
+	unused := data.Values[unusedFlag.Name()].Bool()
+	var aliases []string
+	for alias := range e.Aliases[fileAliaserName] {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+
+	before := copyAliases(e.Aliases)
+	for _, alias := range aliases {
+		v := e.Aliases[fileAliaserName][alias]
+		// Only aliases that were never used are removed with the unused flag.
+		if unused {
+			if e.AliasUses[fileAliaserName][alias] > 0 {
+				continue
+			}
+		} else if !e.missingAlias(v) {
+			continue
+		}
+
+		if dryRun {
+			output.Stdout("Would remove alias %s: %s", alias, strings.Join(v, " "))
+			continue
+		}
+		delete(e.Aliases[fileAliaserName], alias)
+		e.forgetAlias(fileAliaserName, alias)
+		e.MarkChanged()
+	}
+	e.aliasChanges(output, before, data.Values[quietFlag.Name()].Bool())
+	return nil
+}
+
+// missingAlias returns whether or not none of the alias's files exist.
+func (e *Emacs) missingAlias(v []string) bool {
+	for _, f := range v {
+		if isPosition(f) {
+			continue
+		}
+		// Shell commands, bookmarks, and remote files are never missing.
+		if bufferArg(f) || trampRegex.MatchString(f) {
+			return false
+		}
+		if _, err := e.fileSystem().Stat(f); err == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// RenameAlias renames an existing alias.
+func (e *Emacs) RenameAlias(output command.Output, data *command.Data) error {
+	from := data.Values[aliasArg].String()
+	to := data.Values[newAliasArg].String()
+	fs, ok := e.Aliases[fileAliaserName][from]
+	if !ok {
+		return output.Stderr("Alias %q does not exist", from)
+	}
+	if _, ok := e.Aliases[fileAliaserName][to]; ok {
+		return output.Stderr("Alias %q already exists", to)
+	}
+	delete(e.Aliases[fileAliaserName], from)
+	e.Aliases[fileAliaserName][to] = fs
+	if n, ok := e.AliasUses[fileAliaserName][from]; ok {
+		delete(e.AliasUses[fileAliaserName], from)
+		e.AliasUses[fileAliaserName][to] = n
+	}
+	if e.NewFileAliases[fileAliaserName][from] {
+		delete(e.NewFileAliases[fileAliaserName], from)
+		e.NewFileAliases[fileAliaserName][to] = true
+	}
+	e.MarkChanged()
+	output.Stdout("Renamed alias %s to %s", from, to)
+	return nil
+}
+
+// WhichAlias prints the files that an alias points to (one per line) so
+// they can be used in other shell commands.
+func (e *Emacs) WhichAlias(output command.Output, data *command.Data) error {
+	alias := data.Values[aliasArg].String()
+	values, ok := e.Aliases[fileAliaserName][alias]
+	if !ok {
+		return output.Stderr("Alias %q does not exist", alias)
+	}
+	for _, f := range aliasPaths(values) {
+		output.Stdout("%s", f)
+	}
+	return nil
+}
+
+// aliasPaths returns the files in the alias values without any stored line
+// (or column) numbers.
+func aliasPaths(values []string) []string {
+	var r []string
+	for i, v := range values {
+		if i > 0 && isPosition(v) {
+			continue
+		}
+		r = append(r, v)
+	}
+	return r
+}
+
+// OpenDir opens all files in the provided directory. If the depth flag is
+// provided, then only files that many directories down are opened (0 means
+// only the files directly in the directory).
+func (e *Emacs) OpenDir(input *command.Input, output command.Output, data *command.Data, eData *command.ExecuteData) error {
+	dir := data.Values[dirArg].String()
+	fs := e.fileSystem()
+	fi, err := fs.Stat(dir)
+	if err != nil || !fi.IsDir() {
+		return output.Stderr("%q is not a directory", dir)
+	}
+
+	depth, limited := -1, data.Values[depthFlag.Name()].Provided()
+	if limited {
+		depth = data.Values[depthFlag.Name()].Int()
+	}
+
+	var files []*fileOpts
+	err = fs.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if limited && rel != "." && strings.Count(rel, string(filepath.Separator)) >= depth {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		files = append(files, &fileOpts{name: p})
+		return nil
+	})
+	if err != nil {
+		return output.Stderr("failed to walk directory: %v", err)
+	}
+
+	if len(files) == 0 {
+		return output.Stderr("no files found in %q", dir)
+	}
+	return e.openFiles(output, data, eData, files)
+}
+
+// Doctor checks that emacs is properly set up and outputs the result of
+// each check.
+func (e *Emacs) Doctor(output command.Output, data *command.Data) error {
+	var failed int
+	check := func(name string, f func() (string, error)) {
+		res, err := f()
+		if err != nil {
+			failed++
+			output.Stdout("[FAIL] %s: %v", name, err)
+		} else {
+			output.Stdout("[PASS] %s: %s", name, res)
+		}
+	}
+
+	// The checks use the same binaries and socket that files are opened with.
+	eo := e.editorOpts()
+	check("emacs binary", func() (string, error) {
+		p, err := lookPath(eo.emacs())
+		if err != nil {
+			return "", err
+		}
+		v, err := run([]string{fmt.Sprintf("%s --version", eo.emacs())})
+		if err != nil || len(v) == 0 {
+			return "", fmt.Errorf("failed to get emacs version: %v", err)
+		}
+		return fmt.Sprintf("%s (%s)", p, v[0]), nil
+	})
+	check("emacsclient binary", func() (string, error) {
+		return lookPath(eo.emacsclient())
+	})
+	check("emacs daemon", func() (string, error) {
+		if _, err := run([]string{fmt.Sprintf("%s -e '(server-running-p)'", eo.client())}); err != nil {
+			return "", fmt.Errorf("daemon is not running")
+		}
+		return "running", nil
+	})
+	check("daemon socket", func() (string, error) {
+		sl, err := run([]string{fmt.Sprintf(`%s -e '(expand-file-name server-name server-socket-dir)'`, eo.client())})
+		if err != nil || len(sl) == 0 {
+			return "", fmt.Errorf("failed to get socket path: %v", err)
+		}
+		return strings.Trim(sl[0], `"`), nil
+	})
+	check("alias file", func() (string, error) {
+		f, err := ioutil.TempFile("", "emacs_aliases_*.el")
+		if err != nil {
+			return "", fmt.Errorf("failed to create alias file: %v", err)
+		}
+		defer os.Remove(f.Name())
+		if _, err := f.WriteString(e.aliasDotEl()); err != nil {
+			return "", fmt.Errorf("failed to write alias file: %v", err)
+		}
+		f.Close()
+		if _, err := run([]string{fmt.Sprintf("%s --batch -l %s", eo.emacs(), f.Name())}); err != nil {
+			return "", fmt.Errorf("failed to load alias file: %v", err)
+		}
+		return "loadable", nil
+	})
+
+	if failed > 0 {
+		return output.Stderr("%d checks failed", failed)
+	}
+	return nil
+}
+
+// FromGrep opens the files referenced by `path:line:match` lines (the output
+// of `grep -n`). Lines are read from the provided file or from stdin.
+func (e *Emacs) FromGrep(input *command.Input, output command.Output, data *command.Data, eData *command.ExecuteData) error {
+	r := stdin
+	if data.Values[grepFileArg].Provided() {
+		f, err := e.fileSystem().Open(data.Values[grepFileArg].String())
+		if err != nil {
+			return output.Stderr("failed to open grep file: %v", err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	var files []*fileOpts
+	seen := map[string]bool{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		parts := strings.SplitN(line, ":", 3)
+		if len(parts) < 2 {
+			return output.Stderr("invalid grep line %q; expected path:line:match", line)
+		}
+		ln, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return output.Stderr("invalid line number in grep line %q", line)
+		}
+
+		name, err := filepath.Abs(parts[0])
+		if err != nil {
+			return output.Stderr("failed to get absolute path for %q: %v", parts[0], err)
+		}
+		// Only jump to the first match in each file.
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		files = append(files, &fileOpts{name: name, lineNumber: ln})
+	}
+	if err := scanner.Err(); err != nil {
+		return output.Stderr("failed to read grep lines: %v", err)
+	}
+
+	if len(files) == 0 {
+		return output.Stderr("no files found in grep output")
+	}
+	return e.openFiles(output, data, eData, files)
+}
+
+// GitStatus opens the files that are modified or untracked according to
+// `git status`.
+func (e *Emacs) GitStatus(input *command.Input, output command.Output, data *command.Data, eData *command.ExecuteData) error {
+	root, err := run([]string{"git rev-parse --show-toplevel"})
+	if err != nil || len(root) == 0 || root[0] == "" {
+		return output.Stderr("not in a git repository")
+	}
+	sl, err := run([]string{fmt.Sprintf("git -C %q status --porcelain", root[0])})
+	if err != nil {
+		return output.Stderr("failed to get git status: %v", err)
+	}
+
+	var files []*fileOpts
+	for _, line := range sl {
+		// Lines are formatted as "XY PATH" (or "XY ORIG -> PATH" for renames).
+		if len(line) < 4 {
+			continue
+		}
+		status, p := line[:2], line[3:]
+		// Deleted files can't be opened.
+		if strings.Contains(status, "D") {
+			continue
+		}
+		if i := strings.Index(p, " -> "); i >= 0 {
+			p = p[i+len(" -> "):]
+		}
+		// Paths with special characters are quoted.
+		if uq, err := strconv.Unquote(p); err == nil {
+			p = uq
+		}
+		// Untracked directories are listed instead of their files.
+		if strings.HasSuffix(p, "/") {
+			continue
+		}
+		files = append(files, &fileOpts{name: filepath.Join(root[0], p)})
+	}
+
+	if len(files) == 0 {
+		return output.Stderr("no modified or untracked files")
+	}
+	return e.openFiles(output, data, eData, files)
+}
+
+func (e *Emacs) Changed() bool {
+	return e.changed
+}
+
+func (e *Emacs) Cache() map[string][]string {
+	if e.Caches == nil {
+		e.Caches = map[string][]string{}
+	}
+	return e.Caches
+}
+
+func (e *Emacs) AliasDotEl(output command.Output, data *command.Data) error {
+	if !data.Values[outFlag.Name()].Provided() {
+		output.Stdout("%s", e.aliasDotEl())
+		return nil
+	}
+
+	out := data.Values[outFlag.Name()].String()
+	if err := e.fileSystem().MkdirAll(filepath.Dir(out), 0777); err != nil {
+		return output.Stderr("failed to create directory for alias file: %v", err)
+	}
+	if err := e.fileSystem().WriteFile(out, []byte(e.aliasDotEl()), 0644); err != nil {
+		return output.Stderr("failed to write alias file: %v", err)
+	}
+	output.Stdout("Aliases written to %s", out)
+	return nil
+}
+
+// aliasDotEl returns the contents of the lisp file that defines all aliases.
+func (e *Emacs) aliasDotEl() string {
+	var aliases []string
+	for k := range e.Aliases[fileAliaserName] {
+		aliases = append(aliases, k)
+	}
+	sort.Strings(aliases)
+
+	r := []string{
+		"(setq aliasMap",
+		"#s(hash-table",
+		fmt.Sprintf("size %d", len(aliases)),
+		"test equal",
+		"data (",
+	}
+	for _, k := range aliases {
+		fs := aliasPaths(e.Aliases[fileAliaserName][k])
+		if len(fs) == 1 {
+			r = append(r, fmt.Sprintf(`"%s" "%s"`, k, fs[0]))
+			continue
+		}
+		var quoted []string
+		for _, f := range fs {
+			quoted = append(quoted, fmt.Sprintf(`"%s"`, f))
+		}
+		r = append(r, fmt.Sprintf(`"%s" (%s)`, k, strings.Join(quoted, " ")))
+	}
+	r = append(r,
+		")))",
+		"",
+		`(global-set-key (kbd "C-x C-j") (lambda () (interactive)`,
+		`(setq a (read-string "Alias: "))`,
+		`(setq v (gethash a aliasMap))`,
+		`(cond ((stringp v) (find-file v)) (v (mapc (quote find-file) v)) (t (message "Unknown alias: %s" a)))`,
+		"))",
+	)
+	return strings.Join(r, "\n")
+}
+
+func (e *Emacs) Node() *command.Node {
+	// We don't want to cache alias commands. Hence why it comes after.
+	return command.BranchNode(
+		map[string]*command.Node{
+			"el": command.SerialNodes(
+				command.NewFlagNode(outFlag),
+				command.ExecutorNode(e.AliasDotEl),
+			),
+			"doctor": command.SerialNodes(command.ExecutorNode(e.Doctor)),
+			"export": command.SerialNodes(command.ExecutorNode(e.ExportAliases)),
+			"import": command.SerialNodes(
+				command.NewFlagNode(forceFlag, quietFlag),
+				command.ExecutorNode(e.ImportAliases),
+			),
+			"adiff": command.SerialNodes(
+				command.StringListNode(aliasArg, 2, 0, &command.ArgOpt{Completor: e.aliasCompletor()}),
+				command.ExecutorNode(e.AliasDiff),
+			),
+			"prune": command.SerialNodes(
+				command.NewFlagNode(dryRunFlag, unusedFlag, quietFlag),
+				command.ExecutorNode(e.PruneAliases),
+			),
+			// "r" is already used for recent files.
+			"rn": command.SerialNodes(
+				command.StringNode(aliasArg, &command.ArgOpt{Completor: e.aliasCompletor()}),
+				command.StringNode(newAliasArg, nil),
+				command.ExecutorNode(e.RenameAlias),
+			),
+			"which": command.SerialNodes(
+				command.StringNode(aliasArg, &command.ArgOpt{Completor: e.aliasCompletor()}),
+				command.ExecutorNode(e.WhichAlias),
+			),
+			"opendir": command.SerialNodes(
+				command.NewFlagNode(depthFlag),
+				command.StringNode(dirArg, &command.ArgOpt{
+					Completor: &command.Completor{
+						SuggestionFetcher: &command.FileFetcher{
+							IgnoreFiles: true,
+						},
+					},
+					Transformer: command.FileTransformer(),
+				}),
+				command.SimpleProcessor(e.OpenDir, nil),
+			),
+			"r": command.SerialNodes(
+				command.OptionalIntNode(recentArg, &command.ArgOpt{
+					Validators: []command.ArgValidator{command.IntPositive()},
+				}),
+				command.SimpleProcessor(e.Recent, nil),
+			),
+			// "last" is the same as "r", but requires an index.
+			"last": command.SerialNodes(
+				command.IntNode(recentArg, &command.ArgOpt{
+					Validators: []command.ArgValidator{command.IntPositive()},
+				}),
+				command.SimpleProcessor(e.Recent, nil),
+			),
+			"h": command.SerialNodes(
+				command.OptionalIntNode(historicalArg, &command.ArgOpt{
+					Validators: []command.ArgValidator{command.IntNonNegative()},
+				}),
+				command.SimpleProcessor(e.Historical, nil),
+			),
+			"repeat": command.SerialNodes(
+				command.IntNode(repeatArg, &command.ArgOpt{
+					Validators: []command.ArgValidator{command.IntPositive()},
+				}),
+				command.SimpleProcessor(e.Repeat, nil),
+			),
+			"clear": command.SerialNodes(command.ExecutorNode(e.ClearCache)),
+			"show":  command.SerialNodes(command.ExecutorNode(e.ShowCache)),
+			"completion": command.SerialNodes(
+				command.StringNode(shellArg, &command.ArgOpt{
+					Completor: command.SimpleCompletor("bash", "zsh"),
+					Validators: []command.ArgValidator{
+						command.StringOption(func(s string) bool {
+							return s == "bash" || s == "zsh"
+						}, fmt.Errorf(`value must be "bash" or "zsh"`)),
+					},
+				}),
+				command.ExecutorNode(e.CompletionScript),
+			),
+			"find": command.SerialNodes(
+				command.StringNode(regexpArg, nil),
+				command.SimpleProcessor(e.FindFile, nil),
+			),
+			"init":    command.SerialNodes(command.SimpleProcessor(e.InitFile, nil)),
+			"scratch": command.SerialNodes(command.SimpleProcessor(e.Scratch, nil)),
+			"open-group": command.SerialNodes(
+				command.StringNode(openGroupArg, &command.ArgOpt{
+					Completor: e.groupCompletor(),
+				}),
+				command.SimpleProcessor(e.OpenGroup, nil),
+			),
+			"hist": command.SerialNodes(
+				command.StringNode(histFileArg, &command.ArgOpt{
+					Completor: &command.Completor{
+						SuggestionFetcher: &command.FileFetcher{},
+					},
+					Transformer: command.FileTransformer(),
+				}),
+				command.ExecutorNode(e.FileHistory),
+			),
+			"git": command.SerialNodes(command.SimpleProcessor(e.GitStatus, nil)),
+			"fromgrep": command.SerialNodes(
+				command.OptionalStringNode(grepFileArg, &command.ArgOpt{
+					Completor: &command.Completor{
+						SuggestionFetcher: &command.FileFetcher{},
+					},
+					Transformer: command.FileTransformer(),
+				}),
+				command.SimpleProcessor(e.FromGrep, nil),
+			),
+			"settings": e.settingsNode(),
+			"dae": command.SerialNodes(
+				command.NewFlagNode(globalFlag),
+				command.ExecutorNode(e.ToggleDaemonMode),
+			),
+			"dk": command.SerialNodes(command.SimpleProcessor(func(input *command.Input, output command.Output, _ *command.Data, eData *command.ExecuteData) error {
+				eData.Executable = append(eData.Executable,
+					"echo Killing emacs daemon",
+					fmt.Sprintf("%s -e '(kill-emacs)'", e.editorOpts().client()),
+					"echo Success!",
+				)
+				return nil
+			}, nil)),
+			"dstat": command.SerialNodes(command.SimpleProcessor(func(input *command.Input, output command.Output, _ *command.Data, eData *command.ExecuteData) error {
+				eData.Executable = append(eData.Executable,
+					fmt.Sprintf("if %s -e '(server-running-p)' > /dev/null 2>&1; then echo Emacs daemon is running; else echo Emacs daemon is not running; fi", e.editorOpts().client()),
+				)
+				return nil
+			}, nil)),
+			"ds": command.SerialNodes(command.SimpleProcessor(e.StartDaemon, nil)),
+		},
+		e.aliasSummaryNode(func(group string) *command.Node {
+			return command.AliasNode(group, e, e.historyNode(e.emacsArgNode(group)))
+		}),
+		false,
+	)
+}
+
+// settingsNode returns the node for configuring persisted settings.
+func (e *Emacs) settingsNode() *command.Node {
+	return command.BranchNode(
+		map[string]*command.Node{
+			"bin": command.SerialNodes(
+				command.OptionalStringNode(binaryArg, &command.ArgOpt{
+					Completor: &command.Completor{
+						SuggestionFetcher: &command.FileFetcher{},
+					},
+					Transformer: command.FileTransformer(),
+				}),
+				command.ExecutorNode(e.SetBinary),
+			),
+			"flags": command.SerialNodes(
+				command.NewFlagNode(clearFlag),
+				command.StringListNode(extraFlagsArg, 0, command.UnboundedList, nil),
+				command.ExecutorNode(e.SetExtraFlags),
+			),
+			"aliasnocase": command.SerialNodes(
+				command.StringNode(aliasCaseArg, &command.ArgOpt{
+					Completor: command.SimpleCompletor("on", "off"),
+					Validators: []command.ArgValidator{
+						command.StringOption(func(s string) bool {
+							return s == "on" || s == "off"
+						}, fmt.Errorf(`value must be "on" or "off"`)),
+					},
+				}),
+				command.ExecutorNode(func(output command.Output, data *command.Data) error {
+					e.CaseInsensitiveAliases = data.Values[aliasCaseArg].String() == "on"
+					e.MarkChanged()
+					if e.CaseInsensitiveAliases {
+						output.Stdout("Case-insensitive aliases activated.")
+					} else {
+						output.Stdout("Case-insensitive aliases deactivated.")
+					}
+					return nil
+				}),
+			),
+			"gui": command.SerialNodes(
+				command.StringNode(guiArg, &command.ArgOpt{
+					Completor: command.SimpleCompletor("on", "off"),
+					Validators: []command.ArgValidator{
+						command.StringOption(func(s string) bool {
+							return s == "on" || s == "off"
+						}, fmt.Errorf(`value must be "on" or "off"`)),
+					},
+				}),
+				command.ExecutorNode(func(output command.Output, data *command.Data) error {
+					e.GUI = data.Values[guiArg].String() == "on"
+					e.MarkChanged()
+					if e.GUI {
+						output.Stdout("GUI mode activated.")
+					} else {
+						output.Stdout("GUI mode deactivated.")
+					}
+					return nil
+				}),
+			),
+			"nocase": command.SerialNodes(
+				command.StringNode(nocaseArg, &command.ArgOpt{
+					Completor: command.SimpleCompletor("on", "off"),
+					Validators: []command.ArgValidator{
+						command.StringOption(func(s string) bool {
+							return s == "on" || s == "off"
+						}, fmt.Errorf(`value must be "on" or "off"`)),
+					},
+				}),
+				command.ExecutorNode(func(output command.Output, data *command.Data) error {
+					e.CaseSensitive = data.Values[nocaseArg].String() == "off"
+					e.MarkChanged()
+					if e.CaseSensitive {
+						output.Stdout("Case-insensitive completion deactivated.")
+					} else {
+						output.Stdout("Case-insensitive completion activated.")
+					}
+					return nil
+				}),
+			),
+			"auto": command.SerialNodes(
+				command.StringNode(autoArg, &command.ArgOpt{
+					Completor: command.SimpleCompletor("on", "off"),
+					Validators: []command.ArgValidator{
+						command.StringOption(func(s string) bool {
+							return s == "on" || s == "off"
+						}, fmt.Errorf(`value must be "on" or "off"`)),
+					},
+				}),
+				command.ExecutorNode(func(output command.Output, data *command.Data) error {
+					e.AutoDaemon = data.Values[autoArg].String() == "on"
+					e.MarkChanged()
+					if e.AutoDaemon {
+						output.Stdout("Automatic daemon detection activated.")
+					} else {
+						output.Stdout("Automatic daemon detection deactivated.")
+					}
+					return nil
+				}),
+			),
+			"order": command.SerialNodes(
+				command.StringNode(orderArg, &command.ArgOpt{
+					Completor: command.SimpleCompletor("normal", "reverse"),
+					Validators: []command.ArgValidator{
+						command.StringOption(func(s string) bool {
+							return s == "normal" || s == "reverse"
+						}, fmt.Errorf(`value must be "normal" or "reverse"`)),
+					},
+				}),
+				command.ExecutorNode(func(output command.Output, data *command.Data) error {
+					e.NormalOrder = data.Values[orderArg].String() == "normal"
+					e.MarkChanged()
+					if e.NormalOrder {
+						output.Stdout("Files will be opened in normal order.")
+					} else {
+						output.Stdout("Files will be opened in reverse order.")
+					}
+					return nil
+				}),
+			),
+			"socket": command.SerialNodes(
+				command.OptionalStringNode(socketArg, nil),
+				command.ExecutorNode(e.SetSocket),
+			),
+			"root": command.SerialNodes(
+				command.OptionalStringNode(rootArg, &command.ArgOpt{
+					Completor: &command.Completor{
+						SuggestionFetcher: &command.FileFetcher{
+							IgnoreFiles: true,
+						},
+					},
+					Transformer: command.FileTransformer(),
+				}),
+				command.ExecutorNode(e.SetRoot),
+			),
+			"limit": command.SerialNodes(
+				command.IntNode(limitArg, &command.ArgOpt{
+					Validators: []command.ArgValidator{command.IntPositive()},
+				}),
+				command.ExecutorNode(e.SetHistoryLimit),
+			),
+			"maxsize": command.SerialNodes(
+				command.OptionalIntNode(maxSizeArg, &command.ArgOpt{
+					Validators: []command.ArgValidator{command.IntNonNegative()},
+				}),
+				command.ExecutorNode(e.SetMaxSize),
+			),
+			"dcd": command.SerialNodes(command.ExecutorNode(func(output command.Output, _ *command.Data) error {
+				e.DaemonChdir = !e.DaemonChdir
+				e.MarkChanged()
+				if e.DaemonChdir {
+					output.Stdout("Daemon file directories activated.")
+				} else {
+					output.Stdout("Daemon file directories deactivated.")
+				}
+				return nil
+			})),
+			"ext": command.SerialNodes(
+				command.OptionalStringNode(extensionArg, nil),
+				command.OptionalStringNode(extLineArg, &command.ArgOpt{
+					Completor: command.SimpleCompletor("end"),
+					Validators: []command.ArgValidator{
+						command.StringOption(func(s string) bool {
+							n, err := strconv.Atoi(s)
+							return s == "end" || (err == nil && n > 0)
+						}, fmt.Errorf(`value must be "end" or a positive line number`)),
+					},
+				}),
+				command.ExecutorNode(e.SetExtensionLine),
+			),
+		},
+		nil,
+		true,
+	)
+}
+
+// aliasSummaryNode wraps the node returned by the provided function so that
+// a summary of all alias changes made by the node is output after execution.
+// The function is given the alias group selected by the group flag.
+func (e *Emacs) aliasSummaryNode(node func(group string) *command.Node) *command.Node {
+	return &command.Node{
+		Processor: &aliasSummary{
+			e:     e,
+			node:  node,
+			flags: command.NewFlagNode(quietFlag, e.completedGroupFlag(), checkFlag, countFlag),
+		},
+	}
+}
+
+type aliasSummary struct {
+	e     *Emacs
+	node  func(group string) *command.Node
+	flags command.Processor
+}
+
+func (as *aliasSummary) Complete(input *command.Input, data *command.Data) *command.CompleteData {
+	// Process the flags first so completion uses the selected group.
+	if cd := as.flags.Complete(input, data); cd != nil {
+		return cd
+	}
+	return completeNodes(as.node(aliasGroup(data)), input, data)
+}
+
+// aliasGroup returns the alias group selected by the group flag.
+func aliasGroup(data *command.Data) string {
+	if data.Values[groupFlag.Name()].Provided() {
+		return data.Values[groupFlag.Name()].String()
+	}
+	return fileAliaserName
+}
+
+func (as *aliasSummary) Execute(input *command.Input, output command.Output, data *command.Data, eData *command.ExecuteData) error {
+	// Process the flags first so they aren't included in any alias values.
+	if err := executeBeforeSeparator(as.flags, input, output, data, eData); err != nil {
+		return err
+	}
+
+	group := aliasGroup(data)
+	groupProvided := data.Values[groupFlag.Name()].Provided()
+
+	// Aliases are listed here so that all groups can be listed and so that
+	// missing files can be checked.
+	if s, ok := input.Peek(); ok && s == "l" && len(input.Remaining()) == 1 {
+		input.Pop()
+		check := data.Values[checkFlag.Name()].Bool()
+		count := data.Values[countFlag.Name()].Bool()
+		if groupProvided {
+			as.e.listAliases(output, group, check, count)
+		} else {
+			as.e.listAllAliases(output, check, count)
+		}
+		return nil
+	}
+
+	// The alias package only deletes aliases by name, so deleting every
+	// alias in a group is handled here.
+	if s, ok := input.Peek(); ok && s == "d" {
+		input.Pop()
+		if err := command.NewFlagNode(allFlag, forceFlag).Execute(input, output, data, eData); err != nil {
+			return err
+		}
+		if data.Values[allFlag.Name()].Bool() {
+			if !input.FullyProcessed() {
+				return output.Stderr("aliases can't be provided with the %q flag", allFlag.Name())
+			}
+			return as.e.deleteAllAliases(output, group, data.Values[forceFlag.Name()].Bool(), data.Values[quietFlag.Name()].Bool())
+		}
+		input.PushFront("d")
+	}
+
+	// Ranked searches are handled here since the alias package always
+	// sorts search results by alias name.
+	if s, ok := input.Peek(); ok && s == "s" {
+		input.Pop()
+		if err := command.NewFlagNode(rankFlag).Execute(input, output, data, eData); err != nil {
+			return err
+		}
+		if data.Values[rankFlag.Name()].Bool() {
+			return executeNodes(command.SerialNodes(
+				command.StringListNode(regexpArg, 1, command.UnboundedList, nil),
+				command.ExecutorNode(func(output command.Output, data *command.Data) error {
+					return as.e.rankAliases(output, group, data.Values[regexpArg].StringList())
+				}),
+			), input, output, data, eData)
+		}
+		input.PushFront("s")
+	}
+
+	// The new file flag is processed before adding an alias so missing files
+	// are created without the flag being stored in the alias.
+	adding := false
+	if s, ok := input.Peek(); ok && s == "a" {
+		input.Pop()
+		if err := command.NewFlagNode(newFileFlag).Execute(input, output, data, eData); err != nil {
+			return err
+		}
+		input.PushFront("a")
+		adding = true
+	}
+
+	if err := as.e.foldAliasCase(input, group); err != nil {
+		return output.Err(err)
+	}
+
+	// Aliases expanded by the alias package never reach the emacs arg node,
+	// so their uses are counted here.
+	if s, ok := input.Peek(); ok && as.e.isAlias(group, s) {
+		as.e.countAliasUse(eData, group, s)
+		as.e.setAliasFlags(data, group, s)
+	}
+
+	before := copyAliases(as.e.Aliases)
+	if err := executeNodes(as.node(group), input, output, data, eData); err != nil {
+		return err
+	}
+
+	// Remember whether or not the alias was added with the new file flag.
+	if adding && data.Values[aliasArg].Provided() {
+		alias := data.Values[aliasArg].String()
+		as.e.forgetAlias(group, alias)
+		if data.Values[newFileArg].Bool() {
+			if as.e.NewFileAliases == nil {
+				as.e.NewFileAliases = map[string]map[string]bool{}
+			}
+			if as.e.NewFileAliases[group] == nil {
+				as.e.NewFileAliases[group] = map[string]bool{}
+			}
+			as.e.NewFileAliases[group][alias] = true
+			as.e.MarkChanged()
+		}
+	}
+
+	addExecutor(eData, func(output command.Output, data *command.Data) error {
+		// Deleted aliases don't keep their stored flags or use counts, so an
+		// alias that is later added with the same name starts fresh.
+		for alias := range before[group] {
+			if _, ok := as.e.Aliases[group][alias]; !ok {
+				as.e.forgetAlias(group, alias)
+			}
+		}
+		as.e.aliasChanges(output, before, data.Values[quietFlag.Name()].Bool())
+		return nil
+	})
+	return nil
+}
+
+// rankAliases outputs the aliases in the provided group that match all of the
+// provided regexps. Aliases with more matches are output first, followed by
+// aliases with earlier matches, and then by alias name.
+func (e *Emacs) rankAliases(output command.Output, group string, patterns []string) error {
+	var rs []*regexp.Regexp
+	for _, p := range patterns {
+		rx, err := regexp.Compile(p)
+		if err != nil {
+			return output.Stderr("Invalid regexp: %v", err)
+		}
+		rs = append(rs, rx)
+	}
+
+	type rankedAlias struct {
+		s       string
+		matches int
+		pos     int
+	}
+	var ras []*rankedAlias
+	for k, v := range e.Aliases[group] {
+		ra := &rankedAlias{s: fmt.Sprintf("%s: %s", k, strings.Join(v, " "))}
+		for _, rx := range rs {
+			ms := rx.FindAllStringIndex(ra.s, -1)
+			if len(ms) == 0 {
+				ra = nil
+				break
+			}
+			ra.matches += len(ms)
+			ra.pos += ms[0][0]
+		}
+		if ra != nil {
+			ras = append(ras, ra)
+		}
+	}
+
+	sort.Slice(ras, func(i, j int) bool {
+		if ras[i].matches != ras[j].matches {
+			return ras[i].matches > ras[j].matches
+		}
+		if ras[i].pos != ras[j].pos {
+			return ras[i].pos < ras[j].pos
+		}
+		return ras[i].s < ras[j].s
+	})
+	for _, ra := range ras {
+		output.Stdout("%s", ra.s)
+	}
+	return nil
+}
+
+// deleteAllAliases deletes every alias in the provided group.
+func (e *Emacs) deleteAllAliases(output command.Output, group string, force, quiet bool) error {
+	if !force {
+		return output.Stderr("include %q flag to delete all aliases in group %q", forceFlag.Name(), group)
+	}
+	before := copyAliases(e.Aliases)
+	n := len(e.Aliases[group])
+	if n > 0 {
+		for alias := range e.Aliases[group] {
+			e.forgetAlias(group, alias)
+		}
+		e.Aliases[group] = map[string][]string{}
+		e.MarkChanged()
+	}
+	e.aliasChanges(output, before, quiet)
+	if !quiet {
+		output.Stdout("Deleted %d alias(es).", n)
+	}
+	return nil
+}
+
+// listAllAliases lists the aliases in every alias group. If the default
+// group is the only group, then its aliases are listed without a header.
+func (e *Emacs) listAllAliases(output command.Output, check, count bool) {
+	var groups []string
+	for group := range e.Aliases {
+		if group != fileAliaserName {
+			groups = append(groups, group)
+		}
+	}
+	sort.Strings(groups)
+	headers := len(groups) > 0
+	if _, ok := e.Aliases[fileAliaserName]; ok || !headers {
+		groups = append([]string{fileAliaserName}, groups...)
+	}
+
+	for _, group := range groups {
+		if headers {
+			output.Stdout("[%s]", group)
+		}
+		e.listAliases(output, group, check, count)
+	}
+}
+
+// listAliases lists the aliases in the provided group. If check is set, then
+// any files that don't exist are annotated. If count is set, then the number
+// of times each alias was used is included.
+func (e *Emacs) listAliases(output command.Output, group string, check, count bool) {
+	var r []string
+	for k, v := range e.Aliases[group] {
+		fs := v
+		if check {
+			fs = nil
+			for _, f := range v {
+				// Shell commands, bookmarks, and remote files can't be checked.
+				if isPosition(f) || bufferArg(f) || trampRegex.MatchString(f) {
+					fs = append(fs, f)
+					continue
+				}
+				if _, err := e.fileSystem().Stat(f); os.IsNotExist(err) {
+					f = fmt.Sprintf("%s (missing)", f)
+				}
+				fs = append(fs, f)
+			}
+		}
+		line := fmt.Sprintf("%s: %s", k, strings.Join(fs, " "))
+		if count {
+			line = fmt.Sprintf("%s (used %d)", line, e.AliasUses[group][k])
+		}
+		r = append(r, line)
+	}
+	sort.Strings(r)
+	for _, v := range r {
+		output.Stdout("%s", v)
+	}
+}
+
+// isPosition returns whether or not the argument is a line (or LINE:COLUMN)
+// position rather than a file.
+func isPosition(s string) bool {
+	_, err := strconv.Atoi(s)
+	return err == nil || s == "$" || lineColRegex.MatchString(s) || symbolRegex.MatchString(s) || percentRegex.MatchString(s)
+}
+
+// forgetAlias removes the stored flags and use count of the alias.
+func (e *Emacs) forgetAlias(group, alias string) {
+	if _, ok := e.AliasUses[group][alias]; ok {
+		delete(e.AliasUses[group], alias)
+		e.MarkChanged()
+	}
+	if _, ok := e.NewFileAliases[group][alias]; ok {
+		delete(e.NewFileAliases[group], alias)
+		e.MarkChanged()
+	}
+}
+
+// setAliasFlags sets any flags that were stored with the alias.
+func (e *Emacs) setAliasFlags(data *command.Data, group, alias string) {
+	if e.NewFileAliases[group][alias] {
+		data.Set(newFileArg, command.BoolValue(true))
+	}
+}
+
+// countAliasUse increments the number of uses of the alias once the command
+// has successfully run.
+func (e *Emacs) countAliasUse(eData *command.ExecuteData, group, alias string) {
+	addExecutor(eData, func(_ command.Output, data *command.Data) error {
+		// Dry runs and invocations without history don't change any state.
+		if data.Values[dryRunFlag.Name()].Bool() || data.Values[noHistoryFlag.Name()].Bool() {
+			return nil
+		}
+		if e.AliasUses == nil {
+			e.AliasUses = map[string]map[string]int{}
+		}
+		if e.AliasUses[group] == nil {
+			e.AliasUses[group] = map[string]int{}
+		}
+		e.AliasUses[group][alias]++
+		e.MarkChanged()
+		return nil
+	})
+}
+
+// isAlias returns whether or not the provided value is an alias in the group.
+func (e *Emacs) isAlias(group, s string) bool {
+	_, ok := e.Aliases[group][s]
+	return ok
+}
+
+// foldAliasCase replaces the next argument with the alias that it matches
+// (ignoring case) if aliases are case-insensitive.
+func (e *Emacs) foldAliasCase(input *command.Input, group string) error {
+	s, ok := input.Peek()
+	if !ok || !e.CaseInsensitiveAliases || e.isAlias(group, s) {
+		return nil
+	}
+	var aliases []string
+	for a := range e.Aliases[group] {
+		aliases = append(aliases, a)
+	}
+	sort.Strings(aliases)
+	for _, a := range aliases {
+		if strings.ToLower(a) == strings.ToLower(s) {
+			// CheckAliases replaces the argument in place so it's still
+			// included in the cache.
+			return input.CheckAliases(1, &staticAliases{map[string]map[string][]string{group: {s: {a}}}}, group, false)
+		}
+	}
+	return nil
+}
+
+// staticAliases is an AliasCLI for a fixed set of aliases.
+type staticAliases struct {
+	m map[string]map[string][]string
+}
+
+func (sa *staticAliases) AliasMap() map[string]map[string][]string {
+	return sa.m
+}
+
+func (sa *staticAliases) MarkChanged() {}
+
+// aliasRefFiles returns the files for the alias, following any references to
+// other aliases. An error is returned if the references form a cycle.
+func (e *Emacs) aliasRefFiles(group, alias string, path []string) ([]string, error) {
+	for _, a := range path {
+		if a == alias {
+			return nil, fmt.Errorf("alias cycle detected: %s", strings.Join(append(path, alias), " -> "))
+		}
+	}
+	path = append(path, alias)
+
+	var fs []string
+	for _, v := range aliasPaths(e.Aliases[group][alias]) {
+		if !e.isAlias(group, v) {
+			fs = append(fs, v)
+			continue
+		}
+		rfs, err := e.aliasRefFiles(group, v, path)
+		if err != nil {
+			return nil, err
+		}
+		fs = append(fs, rfs...)
+	}
+	return fs, nil
+}
+
+// aliasRefs expands file arguments that reference other aliases. When adding
+// an alias, references are consumed as is so the new alias stores them by
+// name (and reflects any later changes to the referenced alias).
+type aliasRefs struct {
+	e     *Emacs
+	group string
+	p     command.Processor
+}
+
+func (ar *aliasRefs) Complete(input *command.Input, data *command.Data) *command.CompleteData {
+	return ar.p.Complete(input, data)
+}
+
+func (ar *aliasRefs) Execute(input *command.Input, output command.Output, data *command.Data, eData *command.ExecuteData) error {
+	if err := ar.e.foldAliasCase(input, ar.group); err != nil {
+		return output.Err(err)
+	}
+	s, ok := input.Peek()
+	if !ok || !ar.e.isAlias(ar.group, s) {
+		return ar.p.Execute(input, output, data, eData)
+	}
 
-	DaemonMode bool
-}
+	// The alias arg is only set when adding an alias.
+	if data.Values[aliasArg].Provided() {
+		fs, err := ar.e.aliasRefFiles(ar.group, s, nil)
+		if err != nil {
+			return output.Err(err)
+		}
+		input.Pop()
+		data.Set(emacsArg, command.StringListValue(append(data.Values[emacsArg].StringList(), fs...)...))
+		return nil
+	}
 
-func (e *Emacs) AliasMap() map[string]map[string][]string {
-	if e.Aliases == nil {
-		e.Aliases = map[string]map[string][]string{}
+	// The first value of an expanded alias isn't checked for aliases again,
+	// so expand it here until it's no longer an alias.
+	seen := map[string]bool{}
+	for ok && ar.e.isAlias(ar.group, s) {
+		if seen[s] {
+			return output.Stderr("alias cycle detected for %q", s)
+		}
+		seen[s] = true
+		ar.e.countAliasUse(eData, ar.group, s)
+		ar.e.setAliasFlags(data, ar.group, s)
+		if err := input.CheckAliases(1, ar.e, ar.group, false); err != nil {
+			return output.Err(err)
+		}
+		if err := ar.e.foldAliasCase(input, ar.group); err != nil {
+			return output.Err(err)
+		}
+		s, ok = input.Peek()
 	}
-	return e.Aliases
+	return ar.p.Execute(input, output, data, eData)
 }
 
-func (e *Emacs) Setup() []string { return nil }
+// executeNodes executes the graph starting at the provided node. This is used
+// by processors that wrap an entire graph.
+func executeNodes(n *command.Node, input *command.Input, output command.Output, data *command.Data, eData *command.ExecuteData) error {
+	for n != nil {
+		if n.Processor != nil {
+			if err := n.Processor.Execute(input, output, data, eData); err != nil {
+				return err
+			}
+		}
 
-func (e *Emacs) MarkChanged() {
-	e.changed = true
-}
+		if n.Edge == nil {
+			break
+		}
 
-// Name returns the name of the CLI.
-func (e *Emacs) Name() string {
-	return "e"
+		var err error
+		if n, err = n.Edge.Next(input, data); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-// Load creates an Emacs object from a JSON string.
-func (e *Emacs) Load(jsn string) error {
-	if jsn == "" {
-		e = &Emacs{}
-		return nil
+// executeBeforeSeparator executes the processor with only the arguments
+// before the "--" separator so that arguments passed through to emacs are
+// left untouched.
+func executeBeforeSeparator(p command.Processor, input *command.Input, output command.Output, data *command.Data, eData *command.ExecuteData) error {
+	rem := input.Remaining()
+	sep := -1
+	for i, s := range rem {
+		if s == "--" {
+			sep = i
+			break
+		}
+	}
+	if sep < 0 {
+		return p.Execute(input, output, data, eData)
 	}
 
-	if err := json.Unmarshal([]byte(jsn), e); err != nil {
-		return fmt.Errorf("failed to unmarshal emacs json: %v", err)
+	head := command.NewInput(rem[:sep], nil)
+	if err := p.Execute(head, output, data, eData); err != nil {
+		return err
 	}
+	input.PopN(len(rem), 0)
+	input.PushFront(append(head.Remaining(), rem[sep:]...)...)
 	return nil
 }
 
-type fileOpts struct {
-	name       string
-	lineNumber int
-}
+// completeNodes gets the completion for the graph starting at the provided node.
+func completeNodes(n *command.Node, input *command.Input, data *command.Data) *command.CompleteData {
+	for n != nil {
+		if n.Processor != nil {
+			if c := n.Processor.Complete(input, data); c != nil {
+				return c
+			}
+		}
 
-// OpenEditor constructs an emacs command to open the specified files.
-func (e *Emacs) OpenEditor(input *command.Input, output command.Output, data *command.Data, eData *command.ExecuteData) error {
-	allowNewFiles := data.Values[newFileArg].Bool()
-	ergs := data.Values[emacsArg].StringList()
+		if n.Edge == nil {
+			break
+		}
 
-	// If only a directory was provided, then just cd into the directory.
-	if len(ergs) == 1 {
-		fi, _ := os.Stat(ergs[0])
-		if fi != nil && fi.IsDir() {
-			eData.Executable = append(eData.Executable, fmt.Sprintf("cd %s", ergs[0]))
-			return nil
+		var err error
+		if n, err = n.Edge.Next(input, data); err != nil {
+			return &command.CompleteData{
+				Error: err,
+			}
 		}
 	}
+	return nil
+}
+
+// historyNode caches the args run for the provided node unless the
+// no-history flag is provided.
+func (e *Emacs) historyNode(n *command.Node) *command.Node {
+	return &command.Node{
+		Processor: &historyProcessor{
+			e:      e,
+			n:      n,
+			cached: command.CacheNode(cacheName, e, n),
+		},
+	}
+}
 
-	files := make([]*fileOpts, 0, len(ergs))
-	il := data.Values[lineArg].IntList()
-	for i, erg := range ergs {
-		// Check file exists, unless --new flag provided.
-		if !allowNewFiles {
-			if _, err := os.Stat(erg); os.IsNotExist(err) {
-				return output.Stderr("file %q does not exist; include %q flag to create it", erg, newFileArg)
+type historyProcessor struct {
+	e      *Emacs
+	n      *command.Node
+	cached *command.Node
+}
+
+func (hp *historyProcessor) Complete(input *command.Input, data *command.Data) *command.CompleteData {
+	return completeNodes(hp.cached, input, data)
+}
+
+func (hp *historyProcessor) Execute(input *command.Input, output command.Output, data *command.Data, eData *command.ExecuteData) error {
+	for i := 0; ; i++ {
+		// Arguments after "--" are passed through to emacs.
+		s, ok := input.PeekAt(i)
+		if !ok || s == "--" {
+			break
+		}
+		// Dry runs aren't cached either since nothing is actually opened.
+		for _, f := range []command.Flag{noHistoryFlag, dryRunFlag} {
+			if s == fmt.Sprintf("--%s", f.Name()) || s == fmt.Sprintf("-%c", f.ShortName()) {
+				return executeNodes(hp.n, input, output, data, eData)
 			}
 		}
+	}
 
-		var iv int
-		if i < len(il) {
-			iv = il[i]
+	// A lone +N (or -N) argument re-runs the cached command with its line
+	// numbers shifted.
+	if rem := input.Remaining(); len(rem) == 1 && relativeLineRegex.MatchString(rem[0]) {
+		if cached, ok := hp.e.Caches[cacheName]; ok {
+			n, _ := strconv.Atoi(rem[0])
+			input.Pop()
+			input.PushFront(shiftLines(hp.e.fileSystem(), cached, n)...)
 		}
-		files = append(files, &fileOpts{erg, iv})
 	}
 
-	getCmd := basic
-	if e.DaemonMode {
-		getCmd = daemon
+	var prev *historyEntry
+	if len(hp.e.History) > 0 {
+		prev = hp.e.History[len(hp.e.History)-1]
 	}
-
-	gotCmd, err := getCmd(data.Values[debugInitFlag.Name()].Bool(), files...)
-	if err != nil {
-		return output.Err(err)
+	if err := executeNodes(hp.cached, input, output, data, eData); err != nil {
+		return err
 	}
-
-	eData.Executable = append(eData.Executable, gotCmd)
+	// Record the cached args with the history entry added by this invocation
+	// (if one was added at all).
+	addExecutor(eData, func(command.Output, *command.Data) error {
+		if len(hp.e.History) == 0 {
+			return nil
+		}
+		if last := hp.e.History[len(hp.e.History)-1]; last != prev {
+			last.Args = hp.e.Caches[cacheName]
+		}
+		return nil
+	})
 	return nil
 }
 
-func (e *Emacs) Changed() bool {
-	return e.changed
+// shiftLines returns a copy of the args with every line number shifted by n
+// (line numbers are never shifted before the first line). If the args don't
+// contain any line numbers, then n is used as the line number of the last file.
+func shiftLines(fs fileSystem, args []string, n int) []string {
+	valueFlags := map[string]bool{}
+	for _, f := range []command.Flag{monitorFlag, withFlag, evalFlag, argsFileFlag} {
+		valueFlags[fmt.Sprintf("--%s", f.Name())] = true
+		valueFlags[fmt.Sprintf("-%c", f.ShortName())] = true
+	}
+	shift := func(line string) string {
+		l, _ := strconv.Atoi(line)
+		if l += n; l < 1 {
+			l = 1
+		}
+		return strconv.Itoa(l)
+	}
+	missing := func(f string) bool {
+		_, err := fs.Stat(f)
+		return os.IsNotExist(err)
+	}
+
+	// Passthrough arguments are left as is.
+	var passthrough []string
+	for i, a := range args {
+		if a == "--" {
+			args, passthrough = args[:i], args[i:]
+			break
+		}
+	}
+
+	var r []string
+	shifted := false
+	for i, a := range args {
+		if i > 0 && valueFlags[args[i-1]] {
+			r = append(r, a)
+			continue
+		}
+		if l, err := strconv.Atoi(a); err == nil && i > 0 && l > 0 {
+			a, shifted = shift(a), true
+		} else if m := lineColRegex.FindStringSubmatch(a); m != nil && i > 0 {
+			a, shifted = fmt.Sprintf("%s:%s", shift(m[1]), m[2]), true
+		} else if m := fileLineColRegex.FindStringSubmatch(a); m != nil && missing(a) {
+			a, shifted = fmt.Sprintf("%s:%s:%s", m[1], shift(m[2]), m[3]), true
+		} else if m := fileLineRegex.FindStringSubmatch(a); m != nil && missing(a) {
+			a, shifted = fmt.Sprintf("%s:%s", m[1], shift(m[2])), true
+		}
+		r = append(r, a)
+	}
+	if !shifted {
+		r = append(r, strconv.Itoa(n))
+	}
+	return append(r, passthrough...)
 }
 
-func (e *Emacs) Cache() map[string][]string {
-	if e.Caches == nil {
-		e.Caches = map[string][]string{}
+// addExecutor adds the provided function to run after any existing executor.
+func addExecutor(eData *command.ExecuteData, f func(command.Output, *command.Data) error) {
+	ex := eData.Executor
+	eData.Executor = func(output command.Output, data *command.Data) error {
+		if ex != nil {
+			if err := ex(output, data); err != nil {
+				return err
+			}
+		}
+		return f(output, data)
 	}
-	return e.Caches
 }
 
-func (e *Emacs) AliasDotEl(output command.Output, data *command.Data) error {
-	var aliases []string
-	for k := range e.Aliases[fileAliaserName] {
-		aliases = append(aliases, k)
+// aliasesFor returns the sorted names of all aliases in the group whose
+// values are the same set of files as the provided files.
+func (e *Emacs) aliasesFor(group string, files []string) []string {
+	want := map[string]bool{}
+	for _, f := range files {
+		want[f] = true
 	}
-	sort.Strings(aliases)
 
-	r := []string{
-		"(setq aliasMap",
-		"#s(hash-table",
-		fmt.Sprintf("size %d", len(aliases)),
-		"test equal",
-		"data (",
+	var r []string
+	for alias, v := range e.Aliases[group] {
+		got := map[string]bool{}
+		for _, f := range v {
+			got[f] = true
+		}
+		if len(got) != len(want) {
+			continue
+		}
+		same := true
+		for f := range got {
+			if !want[f] {
+				same = false
+				break
+			}
+		}
+		if same {
+			r = append(r, alias)
+		}
 	}
-	for _, k := range aliases {
-		v := e.Aliases[fileAliaserName][k]
-		if len(v) != 1 {
-			output.Stderr("skipping %s because it has more than one value")
-		} else {
-			r = append(r, fmt.Sprintf(`"%s" "%s"`, k, v[0]))
+	sort.Strings(r)
+	return r
+}
+
+func copyAliases(aliases map[string]map[string][]string) map[string]map[string][]string {
+	r := map[string]map[string][]string{}
+	for group, m := range aliases {
+		r[group] = map[string][]string{}
+		for alias, v := range m {
+			r[group][alias] = v
 		}
 	}
-	r = append(r,
-		")))",
-		"",
-		`(global-set-key (kbd "C-x C-j") (lambda () (interactive)`,
-		`(setq a (read-string "Alias: "))`,
-		`(setq v (gethash a aliasMap))`,
-		`(if v (find-file v) (message "Unknown alias: %%s" a))`,
-		"))",
-	)
-	output.Stdout(strings.Join(r, "\n"))
-	return nil
+	return r
 }
 
-func (e *Emacs) Node() *command.Node {
-	// We don't want to cache alias commands. Hence why it comes after.
-	return command.BranchNode(
-		// TODO: Make a settings node. But wait until we have more use
-		// cases so we can get an idea of how to actual make that node useful.
-		map[string]*command.Node{
-			"el": command.SerialNodes(command.ExecutorNode(e.AliasDotEl)),
-			"dae": command.SerialNodes(command.ExecutorNode(func(output command.Output, _ *command.Data) error {
-				e.DaemonMode = !e.DaemonMode
-				e.MarkChanged()
-				if e.DaemonMode {
-					output.Stdout("Daemon mode activated.")
-				} else {
-					output.Stdout("Daemon mode deactivated.")
+// aliasChanges outputs which aliases were added, deleted, or updated
+// relative to the provided alias map. A warning is always output when an
+// added alias has the same files as an existing alias.
+func (e *Emacs) aliasChanges(output command.Output, before map[string]map[string][]string, quiet bool) {
+	var groups []string
+	for group := range before {
+		groups = append(groups, group)
+	}
+	for group := range e.Aliases {
+		if _, ok := before[group]; !ok {
+			groups = append(groups, group)
+		}
+	}
+	sort.Strings(groups)
+
+	for _, group := range groups {
+		var aliases []string
+		for alias := range before[group] {
+			aliases = append(aliases, alias)
+		}
+		for alias := range e.Aliases[group] {
+			if _, ok := before[group][alias]; !ok {
+				aliases = append(aliases, alias)
+			}
+		}
+		sort.Strings(aliases)
+
+		for _, alias := range aliases {
+			prev, hadPrev := before[group][alias]
+			cur, hasCur := e.Aliases[group][alias]
+			if !hadPrev {
+				for _, other := range e.aliasesFor(group, cur) {
+					if other != alias {
+						output.Stderr("Warning: alias %q has the same files as existing alias %q", alias, other)
+					}
 				}
-				return nil
-			})),
-			"dk": command.SerialNodes(command.SimpleProcessor(func(input *command.Input, output command.Output, _ *command.Data, eData *command.ExecuteData) error {
-				eData.Executable = append(eData.Executable,
-					"echo Killing emacs daemon",
-					"emacsclient -e '(kill-emacs)'",
-					"echo Success!",
-				)
-				return nil
-			}, nil)),
-			"ds": command.SerialNodes(command.SimpleProcessor(func(input *command.Input, output command.Output, _ *command.Data, eData *command.ExecuteData) error {
-				eData.Executable = append(eData.Executable,
-					"echo Starting emacs daemon",
-					"emacs --daemon",
-					"echo Success!",
-				)
-				return nil
-			}, nil)),
-		},
-		command.AliasNode(fileAliaserName, e, command.CacheNode(cacheName, e, e.emacsArgNode())),
-		false,
-	)
+			}
+
+			if quiet {
+				continue
+			}
+			switch {
+			case !hadPrev:
+				output.Stdout("Added alias %s: %s", alias, strings.Join(cur, " "))
+			case !hasCur:
+				output.Stdout("Deleted alias %s: %s", alias, strings.Join(prev, " "))
+			case !sliceEquals(prev, cur):
+				output.Stdout("Updated alias %s: %s", alias, strings.Join(cur, " "))
+			}
+		}
+	}
 }
 
-func (e *Emacs) emacsArgNode() *command.Node {
-	completor := &command.Completor{
+// expandPath expands a leading ~ and any environment variables in the
+// provided file argument.
+func expandPath(f string) (string, error) {
+	if f == "~" || strings.HasPrefix(f, "~/") {
+		home, err := userHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get home directory: %v", err)
+		}
+		f = home + f[1:]
+	}
+	return os.Expand(f, getenv), nil
+}
+
+// emacsArgNode returns the node for opening files. Aliases are expanded
+// using the provided alias group.
+func (e *Emacs) emacsArgNode(group string) *command.Node {
+	fileFetcher := &command.FileFetcher{
 		Distinct: true,
-		SuggestionFetcher: &command.FileFetcher{
-			Distinct: true,
-			IgnoreFunc: func(v *command.Value, d *command.Data) []string {
-				return d.Values[emacsArg].StringList()
-			},
+		IgnoreFunc: func(v *command.Value, d *command.Data) []string {
+			return d.Values[emacsArg].StringList()
 		},
 	}
+	completor := &command.Completor{
+		Distinct: true,
+		SuggestionFetcher: command.SimpleFetcher(func(v *command.Value, d *command.Data) *command.Completion {
+			// Complete the path portion of FILE:LINE arguments.
+			if m := partialFileLineRegex.FindStringSubmatch(v.String()); m != nil {
+				if _, err := e.fileSystem().Stat(v.String()); os.IsNotExist(err) {
+					return completeFileLine(fileFetcher.Fetch(command.StringValue(m[1]), d), m[2])
+				}
+			}
+
+			ff := *fileFetcher
+			if e.CaseSensitive {
+				// FileFetcher always ignores case so only include files that
+				// match the exact prefix.
+				_, laFile := filepath.Split(v.String())
+				ff.Regexp = regexp.MustCompile(fmt.Sprintf("^%s", regexp.QuoteMeta(laFile)))
+			}
+			return e.withRecentDirs(ff.Fetch(v, d), d)
+		}),
+	}
 
 	opt := &command.ArgOpt{
 		Alias: &command.AliasOpt{
-			AliasName: fileAliaserName,
+			AliasName: group,
 			AliasCLI:  e,
 		},
-		Completor:   completor,
-		Transformer: command.FileTransformer(),
+		Completor: completor,
+		// List file arguments (including stdin), remote paths, shell
+		// commands, and bookmarks are read as is.
+		Transformer: command.SimpleTransformer(command.StringType, func(v *command.Value) (*command.Value, error) {
+			if v.String() == stdinFileList || strings.HasPrefix(v.String(), listFilePrefix) || bufferArg(v.String()) || trampRegex.MatchString(v.String()) {
+				return v, nil
+			}
+			f, err := expandPath(v.String())
+			if err != nil {
+				return nil, err
+			}
+			if e.Root != "" && !filepath.IsAbs(f) {
+				return command.StringValue(filepath.Join(e.Root, f)), nil
+			}
+			return command.FileTransformer().Transform(command.StringValue(f))
+		}),
 		CustomSet: func(v *command.Value, d *command.Data) {
 			// TODO: CustomSet shouldn't be run if v wasn't provided.
 			// fix this in command package.
 			if !v.Provided() {
 				return
 			}
-			slv, ok := d.Values[emacsArg]
-			if !ok {
-				d.Set(emacsArg, command.StringListValue(v.String()))
-				return
-			} else {
-				d.Set(emacsArg, command.StringListValue(append(slv.StringList(), v.String())...))
+			// Split off a trailing line (and column) number (unless the file
+			// actually exists with that name).
+			name, line, col := v.String(), 0, 0
+			if _, err := e.fileSystem().Stat(name); os.IsNotExist(err) && !bufferArg(name) {
+				if m := fileLineColRegex.FindStringSubmatch(name); m != nil {
+					name = m[1]
+					line, _ = strconv.Atoi(m[2])
+					col, _ = strconv.Atoi(m[3])
+				} else if m := fileLineRegex.FindStringSubmatch(name); m != nil {
+					name = m[1]
+					line, _ = strconv.Atoi(m[2])
+				}
+			}
+			d.Set(emacsArg, command.StringListValue(append(d.Values[emacsArg].StringList(), name)...))
+			if line != 0 {
+				setPosition(d, lineArg, line)
+			}
+			if col != 0 {
+				setPosition(d, columnArg, col)
 			}
 		},
 	}
 
 	intOpt := &command.ArgOpt{
 		CustomSet: func(v *command.Value, d *command.Data) {
-			sl := d.Values[emacsArg].StringList()
-			il := d.Values[lineArg].IntList()
-			for i := len(il); i < len(sl)-1; i++ {
-				il = append(il, 0)
-			}
-			il = append(il, v.Int())
-			d.Set(lineArg, command.IntListValue(il...))
+			setPosition(d, lineArg, v.Int())
+		},
+	}
+
+	lineColOpt := &command.ArgOpt{
+		CustomSet: func(v *command.Value, d *command.Data) {
+			// The edge only routes valid LINE:COL arguments here.
+			m := lineColRegex.FindStringSubmatch(v.String())
+			line, _ := strconv.Atoi(m[1])
+			col, _ := strconv.Atoi(m[2])
+			setPosition(d, lineArg, line)
+			setPosition(d, columnArg, col)
 		},
 	}
 
 	n := &command.Node{
-		Processor: command.StringNode(emacsArg, opt),
+		Processor: &aliasRefs{
+			e:     e,
+			group: group,
+			p:     command.StringNode(emacsArg, opt),
+		},
 	}
 	in := &command.Node{
 		Processor: command.IntNode(lineArg, intOpt),
 		//Edge:      command.SimpleEdge(n),
 	}
+	lcn := &command.Node{
+		Processor: command.StringNode(lineColArg, lineColOpt),
+	}
+	// With the marks flag, all additional line numbers are for the same file.
+	mn := &command.Node{
+		Processor: command.IntNode(markTokenArg, &command.ArgOpt{
+			CustomSet: func(v *command.Value, d *command.Data) {
+				d.Set(marksArg, command.IntListValue(append(d.Values[marksArg].IntList(), v.Int())...))
+			},
+		}),
+	}
+	sn := &command.Node{
+		Processor: command.StringNode(atSymbolArg, &command.ArgOpt{
+			CustomSet: func(v *command.Value, d *command.Data) {
+				setStringPosition(d, symbolArg, symbolRegex.FindStringSubmatch(v.String())[1])
+			},
+		}),
+	}
+	pctNode := &command.Node{
+		Processor: command.StringNode(pctTokenArg, &command.ArgOpt{
+			Validators: []command.ArgValidator{
+				command.StringOption(func(s string) bool {
+					p, err := strconv.Atoi(percentRegex.FindStringSubmatch(s)[1])
+					return err == nil && p <= 100
+				}, fmt.Errorf("percentage must be between 0 and 100")),
+			},
+			CustomSet: func(v *command.Value, d *command.Data) {
+				setStringPosition(d, percentArg, percentRegex.FindStringSubmatch(v.String())[1])
+			},
+		}),
+	}
+	endNode := &command.Node{
+		Processor: command.StringNode(endTokenArg, &command.ArgOpt{
+			CustomSet: func(v *command.Value, d *command.Data) {
+				setPosition(d, endArg, 1)
+			},
+		}),
+	}
+	bookmarkNode := &command.Node{
+		Processor: command.StringNode(bookmarkTokenArg, &command.ArgOpt{
+			CustomSet: func(v *command.Value, d *command.Data) {
+				d.Set(emacsArg, command.StringListValue(append(d.Values[emacsArg].StringList(), v.String())...))
+			},
+		}),
+	}
 	next := command.SerialNodes(command.SimpleProcessor(e.OpenEditor, nil))
 	n.Edge = &emacsEdge{
-		next:    next,
-		eNode:   n,
-		intNode: in,
+		e:            e,
+		next:         next,
+		eNode:        n,
+		intNode:      in,
+		lcNode:       lcn,
+		symNode:      sn,
+		endNode:      endNode,
+		pctNode:      pctNode,
+		marksNode:    mn,
+		bookmarkNode: bookmarkNode,
 	}
 	in.Edge = &intEdge{
-		next:  next,
-		eNode: n,
+		next:      next,
+		eNode:     n,
+		marksNode: mn,
 	}
+	lcn.Edge = in.Edge
+	mn.Edge = in.Edge
+	sn.Edge = in.Edge
+	endNode.Edge = in.Edge
+	pctNode.Edge = in.Edge
+	bookmarkNode.Edge = in.Edge
 
 	return command.SerialNodesTo(n,
 		command.NewFlagNode(
-			command.BoolFlag(newFileArg, 'n'),
+			passthroughFlag{},
+			newFileFlag,
 			debugInitFlag,
+			readOnlyFlag,
+			dryRunFlag,
+			sudoFlag,
+			monitorFlag,
+			newFrameFlag,
+			noWaitFlag,
+			verboseFlag,
+			withFlag,
+			writableFlag,
+			copyPathFlag,
+			noHistoryFlag,
+			argsFileFlag,
+			forceOpenFlag,
+			evalFlag,
+			largeFlag,
+			blameFlag,
+			marksFlag,
+			touchFlag,
+			diredFlag,
 		),
+		// Files in an args file are opened the same way as a list file
+		// argument. The argument is added after all other arguments so it
+		// isn't included in the cached (or historical) args.
+		command.SimpleProcessor(func(input *command.Input, _ command.Output, data *command.Data, _ *command.ExecuteData) error {
+			if data.Values[argsFileFlag.Name()].Provided() {
+				input.PushFrontAt(len(input.Remaining()), listFilePrefix+data.Values[argsFileFlag.Name()].String())
+			}
+			return nil
+		}, nil),
 	)
 }
 
+// completeFileLine adds the line suffix to the path completion of a FILE:LINE
+// argument. The suffix can only be kept when the path completes to a single
+// file, so otherwise the matching files are only listed (if possible).
+func completeFileLine(c *command.Completion, suffix string) *command.Completion {
+	if c == nil {
+		return nil
+	}
+	if len(c.Suggestions) == 1 && !strings.HasSuffix(c.Suggestions[0], "/") {
+		c.Suggestions[0] += suffix
+		return c
+	}
+	if c.DontComplete {
+		return c
+	}
+	return nil
+}
+
+// withRecentDirs adds any cached directories that match the value being
+// completed to the file completion.
+func (e *Emacs) withRecentDirs(c *command.Completion, d *command.Data) *command.Completion {
+	sl := d.Values[emacsArg].StringList()
+	var lastArg string
+	if len(sl) > 0 {
+		lastArg = sl[len(sl)-1]
+	}
+	included := map[string]bool{}
+	for i := 0; i < len(sl)-1; i++ {
+		included[strings.TrimSuffix(sl[i], "/")] = true
+	}
+
+	var dirs []string
+	for _, dir := range e.Caches[cacheName] {
+		if !strings.HasPrefix(dir, lastArg) || included[dir] {
+			continue
+		}
+		if fi, err := e.fileSystem().Stat(dir); err == nil && fi.IsDir() {
+			dirs = append(dirs, fmt.Sprintf("%s/", dir))
+		}
+	}
+
+	if len(dirs) == 0 {
+		return c
+	}
+	if c == nil {
+		return &command.Completion{
+			Suggestions: dirs,
+		}
+	}
+	// The file completion takes precedence if it can be autocompleted.
+	if !c.DontComplete {
+		return c
+	}
+	c.Suggestions = append(c.Suggestions, dirs...)
+	return c
+}
+
+// setPosition sets the position value (line or column) for the most
+// recently provided file argument.
+func setPosition(d *command.Data, arg string, pos int) {
+	sl := d.Values[emacsArg].StringList()
+	il := d.Values[arg].IntList()
+	for i := len(il); i < len(sl)-1; i++ {
+		il = append(il, 0)
+	}
+	il = append(il, pos)
+	d.Set(arg, command.IntListValue(il...))
+}
+
+// setStringPosition is the same as setPosition, but for string values.
+func setStringPosition(d *command.Data, arg string, pos string) {
+	sl := d.Values[emacsArg].StringList()
+	pl := d.Values[arg].StringList()
+	for i := len(pl); i < len(sl)-1; i++ {
+		pl = append(pl, "")
+	}
+	pl = append(pl, pos)
+	d.Set(arg, command.StringListValue(pl...))
+}
+
+// passthroughFlag is the "--" separator. All arguments after it are passed
+// to emacs verbatim.
+type passthroughFlag struct{}
+
+// Name returns an empty name so the separator is "--".
+func (passthroughFlag) Name() string { return "" }
+
+// ShortName returns '-' so the short separator is also "--".
+func (passthroughFlag) ShortName() rune { return '-' }
+
+func (passthroughFlag) Processor() command.Processor {
+	return command.StringListNode(passthroughArg, 0, command.UnboundedList, nil)
+}
+
 type intEdge struct {
-	next  *command.Node
-	eNode *command.Node
+	next      *command.Node
+	eNode     *command.Node
+	marksNode *command.Node
 }
 
 func (ie *intEdge) Next(input *command.Input, data *command.Data) (*command.Node, error) {
-	if _, ok := input.Peek(); !ok {
+	s, ok := input.Peek()
+	if !ok {
 		return ie.next, nil
 	}
+	if _, err := strconv.Atoi(s); err == nil && data.Values[marksFlag.Name()].Bool() {
+		return ie.marksNode, nil
+	}
 	return ie.eNode, nil
 }
 
 // TODO: make helper function command.EdgeFromFunc(func(...) (node, error)) {...}
 type emacsEdge struct {
-	next    *command.Node
-	eNode   *command.Node
-	intNode *command.Node
+	e            *Emacs
+	next         *command.Node
+	eNode        *command.Node
+	intNode      *command.Node
+	lcNode       *command.Node
+	symNode      *command.Node
+	endNode      *command.Node
+	pctNode      *command.Node
+	marksNode    *command.Node
+	bookmarkNode *command.Node
 }
 
 func (ee *emacsEdge) Next(input *command.Input, data *command.Data) (*command.Node, error) {
@@ -308,10 +3362,41 @@ func (ee *emacsEdge) Next(input *command.Input, data *command.Data) (*command.No
 		return ee.next, nil
 	}
 
+	// This is checked first since "-0" is also an integer.
+	if s == "$" || s == "-0" {
+		return ee.endNode, nil
+	}
+
 	if _, err := strconv.Atoi(s); err == nil {
+		// The marks flag sends line numbers for a file that already has one
+		// (e.g. FILE:LINE) to the marks node.
+		if data.Values[marksFlag.Name()].Bool() && len(data.Values[lineArg].IntList()) >= len(data.Values[emacsArg].StringList()) {
+			return ee.marksNode, nil
+		}
 		return ee.intNode, nil
 	}
 
+	if lineColRegex.MatchString(s) {
+		return ee.lcNode, nil
+	}
+
+	if percentRegex.MatchString(s) {
+		return ee.pctNode, nil
+	}
+
+	// @@bookmark arguments skip alias expansion and the file checks.
+	if strings.HasPrefix(s, bookmarkPrefix) && len(data.Values[emacsArg].StringList()) < 2 {
+		return ee.bookmarkNode, nil
+	}
+
+	// @symbol arguments after a file are searched for, unless a list file
+	// with that name exists.
+	if m := symbolRegex.FindStringSubmatch(s); m != nil && len(data.Values[emacsArg].StringList()) > 0 {
+		if _, err := ee.e.fileSystem().Stat(m[1]); os.IsNotExist(err) {
+			return ee.symNode, nil
+		}
+	}
+
 	if len(data.Values[emacsArg].StringList()) >= 2 {
 		return ee.next, nil
 	}
@@ -1,8 +1,12 @@
 package emacs
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -35,6 +39,7 @@ func TestLoad(t *testing.T) {
 			name: "properly unmarshals",
 			json: fmt.Sprintf(`{"Aliases":{"%s":{"city":["catan", "oreAndWheat"]}},"PreviousExecutions":null}`, fileAliaserName),
 			want: &Emacs{
+				Version: currentVersion,
 				Aliases: map[string]map[string][]string{
 					fileAliaserName: {
 						"city": {"catan", "oreAndWheat"},
@@ -46,6 +51,7 @@ func TestLoad(t *testing.T) {
 			name: "properly unmarshals with daemon",
 			json: fmt.Sprintf(`{"DaemonMode": true, "Aliases":{"%s":{"city":["catan", "oreAndWheat"]}},"PreviousExecutions":null}`, fileAliaserName),
 			want: &Emacs{
+				Version: currentVersion,
 				Aliases: map[string]map[string][]string{
 					fileAliaserName: {
 						"city": {"catan", "oreAndWheat"},
@@ -54,6 +60,56 @@ func TestLoad(t *testing.T) {
 				DaemonMode: true,
 			},
 		},
+		{
+			name: "properly unmarshals with root",
+			json: `{"Root": "/path/to/project"}`,
+			want: &Emacs{
+				Version: currentVersion,
+				Root:    "/path/to/project",
+			},
+		},
+		{
+			name: "migrates v0 cache into history",
+			json: fmt.Sprintf(`{"Caches":{"%s":["alpha.go", "12"]}}`, cacheName),
+			want: &Emacs{
+				Version: currentVersion,
+				Caches: map[string][]string{
+					cacheName: {"alpha.go", "12"},
+				},
+				History: []*historyEntry{
+					{Args: []string{"alpha.go", "12"}},
+				},
+			},
+		},
+		{
+			name: "v0 migration keeps existing history",
+			json: fmt.Sprintf(`{"Caches":{"%s":["alpha.go"]},"History":[{"Files":["/beta.go"]}]}`, cacheName),
+			want: &Emacs{
+				Version: currentVersion,
+				Caches: map[string][]string{
+					cacheName: {"alpha.go"},
+				},
+				History: []*historyEntry{
+					{Files: []string{"/beta.go"}},
+				},
+			},
+		},
+		{
+			name: "doesn't migrate current version",
+			json: fmt.Sprintf(`{"Version":%d,"Caches":{"%s":["alpha.go"]}}`, currentVersion, cacheName),
+			want: &Emacs{
+				Version: currentVersion,
+				Caches: map[string][]string{
+					cacheName: {"alpha.go"},
+				},
+			},
+		},
+		{
+			name:    "errors on newer version",
+			json:    `{"Version":1000}`,
+			want:    &Emacs{Version: 1000},
+			WantErr: "emacs json version (1000) is newer than the supported version",
+		},
 	} {
 		t.Run(test.name, func(t *testing.T) {
 			e := &Emacs{}
@@ -74,6 +130,98 @@ func TestLoad(t *testing.T) {
 	}
 }
 
+func TestAliasOverlay(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		json     string
+		overlay  string
+		want     *Emacs
+		wantJSON string
+		WantErr  string
+	}{
+		{
+			name:    "overlays aliases on empty json",
+			overlay: `{"fileAliases":{"city":["catan"]}}`,
+			want: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"city": {"catan"},
+					},
+				},
+			},
+			wantJSON: `{"Version":1,"Aliases":{"fileAliases":{}},"Caches":null,"History":null,"DaemonMode":false,"DirDaemonMode":null,"AutoDaemon":false,"DaemonChdir":false,"Binary":"","Root":"","Socket":"","CaseSensitive":false,"NormalOrder":false,"GUI":false,"ExtraFlags":null,"HistoryLimit":0,"MaxSize":0,"AliasUses":null,"NewFileAliases":null,"CaseInsensitiveAliases":false,"ExtensionLines":null}`,
+		},
+		{
+			name:    "overlay wins on conflicts",
+			json:    `{"Aliases":{"fileAliases":{"city":["oreAndWheat"],"salt":["NaCl"]}}}`,
+			overlay: `{"fileAliases":{"city":["catan"],"water":["H2O"]}}`,
+			want: &Emacs{
+				Version: currentVersion,
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"city":  {"catan"},
+						"salt":  {"NaCl"},
+						"water": {"H2O"},
+					},
+				},
+			},
+			wantJSON: `{"Version":1,"Aliases":{"fileAliases":{"city":["oreAndWheat"],"salt":["NaCl"]}},"Caches":null,"History":null,"DaemonMode":false,"DirDaemonMode":null,"AutoDaemon":false,"DaemonChdir":false,"Binary":"","Root":"","Socket":"","CaseSensitive":false,"NormalOrder":false,"GUI":false,"ExtraFlags":null,"HistoryLimit":0,"MaxSize":0,"AliasUses":null,"NewFileAliases":null,"CaseInsensitiveAliases":false,"ExtensionLines":null}`,
+		},
+		{
+			name:    "errors on invalid overlay json",
+			overlay: "}",
+			want:    &Emacs{},
+			WantErr: "failed to unmarshal alias overlay json",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			f, err := ioutil.TempFile("", "emacs_overlay")
+			if err != nil {
+				t.Fatalf("failed to create overlay file: %v", err)
+			}
+			defer os.Remove(f.Name())
+			if _, err := f.WriteString(test.overlay); err != nil {
+				t.Fatalf("failed to write overlay file: %v", err)
+			}
+			f.Close()
+
+			oldGetenv := getenv
+			getenv = func(key string) string {
+				if key == overlayEnvVar {
+					return f.Name()
+				}
+				return ""
+			}
+			defer func() { getenv = oldGetenv }()
+
+			e := &Emacs{}
+			err = e.Load(test.json)
+			if err != nil && test.WantErr == "" {
+				t.Fatalf("Load(%v) returned error (%v); want nil", test.json, err)
+			} else if err == nil && test.WantErr != "" {
+				t.Fatalf("Load(%v) returned nil; want error (%v)", test.json, test.WantErr)
+			} else if err != nil && test.WantErr != "" && !strings.Contains(err.Error(), test.WantErr) {
+				t.Fatalf("Load(%v) returned error (%v); want (%v)", test.json, err, test.WantErr)
+			}
+
+			if diff := cmp.Diff(test.want, e, cmpopts.IgnoreUnexported(Emacs{}), cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("Load(%v) produced emacs diff (-want, +got):\n%s", test.json, diff)
+			}
+
+			if test.WantErr != "" {
+				return
+			}
+			b, err := json.Marshal(e)
+			if err != nil {
+				t.Fatalf("json.Marshal(%v) returned error: %v", e, err)
+			}
+			if diff := cmp.Diff(test.wantJSON, string(b)); diff != "" {
+				t.Errorf("json.Marshal(%v) produced diff (-want, +got):\n%s", e, diff)
+			}
+		})
+	}
+}
+
 func TestAutocomplete(t *testing.T) {
 	e := &Emacs{
 		Aliases: map[string]map[string][]string{fileAliaserName: {
@@ -85,6 +233,7 @@ func TestAutocomplete(t *testing.T) {
 
 	for _, test := range []struct {
 		name string
+		e    *Emacs
 		ctc  *command.CompleteTestCase
 	}{
 		{
@@ -93,12 +242,17 @@ func TestAutocomplete(t *testing.T) {
 				Want: []string{
 					".git/",
 					"basic.go",
+					"clipboard.go",
 					"emacs.go",
 					"emacs_test.go",
+					"filesystem.go",
 					"go.mod",
 					"go.sum",
 					"README.md",
 					"testing/",
+					"writable_unix.go",
+					"writable_unix_test.go",
+					"writable_windows.go",
 					" ",
 				},
 				WantData: &command.Data{
@@ -108,6 +262,48 @@ func TestAutocomplete(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "suggests settings",
+			ctc: &command.CompleteTestCase{
+				Args: []string{"settings", ""},
+				Want: []string{
+					"aliasnocase",
+					"auto",
+					"bin",
+					"dcd",
+					"ext",
+					"flags",
+					"gui",
+					"limit",
+					"maxsize",
+					"nocase",
+					"order",
+					"root",
+					"socket",
+				},
+			},
+		},
+		{
+			name: "suggests groups for open-group",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {"uno": {"/alpha.go"}},
+					"docs":          {"readme": {"/README.md"}},
+				},
+			},
+			ctc: &command.CompleteTestCase{
+				Args: []string{"open-group", ""},
+				Want: []string{
+					"docs",
+					fileAliaserName,
+				},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						openGroupArg: command.StringValue(""),
+					},
+				},
+			},
+		},
 		{
 			name: "file suggestions ignore case",
 			ctc: &command.CompleteTestCase{
@@ -123,6 +319,38 @@ func TestAutocomplete(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "file suggestions are case sensitive when configured",
+			e: &Emacs{
+				CaseSensitive: true,
+			},
+			ctc: &command.CompleteTestCase{
+				Args: []string{"testing/L"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue("testing/L"),
+					},
+				},
+			},
+		},
+		{
+			name: "case sensitive file suggestions match exact prefix",
+			e: &Emacs{
+				CaseSensitive: true,
+			},
+			ctc: &command.CompleteTestCase{
+				Args: []string{"testing/l"},
+				Want: []string{
+					"testing/luckyNumber",
+					"testing/luckyNumber_",
+				},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue("testing/l"),
+					},
+				},
+			},
+		},
 		{
 			name: "suggests only files after first command",
 			ctc: &command.CompleteTestCase{
@@ -130,12 +358,17 @@ func TestAutocomplete(t *testing.T) {
 				Want: []string{
 					".git/",
 					"basic.go",
+					"clipboard.go",
 					"emacs.go",
 					"emacs_test.go",
+					"filesystem.go",
 					"go.mod",
 					"go.sum",
 					"README.md",
 					"testing/",
+					"writable_unix.go",
+					"writable_unix_test.go",
+					"writable_windows.go",
 					" ",
 				},
 				WantData: &command.Data{
@@ -159,6 +392,63 @@ func TestAutocomplete(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "completes path of file:line arguments",
+			ctc: &command.CompleteTestCase{
+				Args: []string{"testing/alpha.g:12"},
+				Want: []string{
+					"testing/alpha.go:12",
+				},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue("testing/alpha.g"),
+						lineArg:  command.IntListValue(12),
+					},
+				},
+			},
+		},
+		{
+			name: "completes path of file:line arguments without line",
+			ctc: &command.CompleteTestCase{
+				Args: []string{"testing/alpha.t:"},
+				Want: []string{
+					"testing/alpha.txt:",
+				},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue("testing/alpha.t:"),
+					},
+				},
+			},
+		},
+		{
+			name: "completes path of file:line:col arguments",
+			ctc: &command.CompleteTestCase{
+				Args: []string{"testing/alpha.g:12:3"},
+				Want: []string{
+					"testing/alpha.go:12:3",
+				},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg:  command.StringListValue("testing/alpha.g"),
+						lineArg:   command.IntListValue(12),
+						columnArg: command.IntListValue(3),
+					},
+				},
+			},
+		},
+		{
+			name: "doesn't complete ambiguous file:line arguments",
+			ctc: &command.CompleteTestCase{
+				Args: []string{"testing/alp:12"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue("testing/alp"),
+						lineArg:  command.IntListValue(12),
+					},
+				},
+			},
+		},
 		{
 			name: "doesn't include files a directory down that are already included",
 			ctc: &command.CompleteTestCase{
@@ -173,6 +463,49 @@ func TestAutocomplete(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "suggests cached directories",
+			e: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "nested", "sub", "deeper")},
+				},
+			},
+			ctc: &command.CompleteTestCase{
+				Args: []string{absPath(t, "nested") + "/"},
+				Want: []string{
+					absPath(t, "nested", "sub", "deeper") + "/",
+					"sub/",
+					"top.txt",
+					" ",
+				},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue(absPath(t, "nested") + "/"),
+					},
+				},
+			},
+		},
+		{
+			name: "doesn't suggest cached directories already included",
+			e: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "nested", "sub", "deeper")},
+				},
+			},
+			ctc: &command.CompleteTestCase{
+				Args: []string{absPath(t, "nested", "sub", "deeper"), absPath(t, "nested") + "/"},
+				Want: []string{
+					"sub/",
+					"top.txt",
+					" ",
+				},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue(absPath(t, "nested", "sub", "deeper"), absPath(t, "nested")+"/"),
+					},
+				},
+			},
+		},
 		// aliasFetcher tests
 		{
 			name: "suggests only aliases for delete",
@@ -189,6 +522,20 @@ func TestAutocomplete(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "suggests aliases for adiff",
+			ctc: &command.CompleteTestCase{
+				Args: []string{"adiff", "salt", ""},
+				Want: []string{
+					"city",
+				},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						aliasArg: command.StringListValue("salt", ""),
+					},
+				},
+			},
+		},
 		// GetAlias
 		{
 			name: "suggests aliases for get",
@@ -219,779 +566,7460 @@ func TestAutocomplete(t *testing.T) {
 				},
 			},
 		},
-	} {
-		t.Run(test.name, func(t *testing.T) {
-			test.ctc.Node = e.Node()
-			command.CompleteTest(t, test.ctc, nil)
-		})
-	}
-}
-
-func TestEmacsExecution(t *testing.T) {
-	for _, test := range []struct {
-		name string
-		e    *Emacs
-		etc  *command.ExecuteTestCase
-		want *Emacs
-	}{
-		// Daemon mode.
-		{
-			name: "daemon toggle doesn't accept args",
-			etc: &command.ExecuteTestCase{
-				Args:       []string{"dae", "mon"},
-				WantErr:    fmt.Errorf("Unprocessed extra args: [mon]"),
-				WantStderr: []string{"Unprocessed extra args: [mon]"},
-			},
-		},
 		{
-			name: "toggles daemon mode to true",
-			etc: &command.ExecuteTestCase{
-				Args:       []string{"dae"},
-				WantStdout: []string{"Daemon mode activated."},
+			name: "suggests aliases from the selected group",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"salt": {path("compounds", "sodiumChloride")},
+					},
+					"docs": {
+						"readme": {"README.md"},
+						"notes":  {"notes.org"},
+					},
+				},
 			},
-			want: &Emacs{
-				DaemonMode: true,
+			ctc: &command.CompleteTestCase{
+				Args: []string{"g", "--group", "docs", ""},
+				Want: []string{
+					"notes",
+					"readme",
+				},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						groupFlag.Name(): command.StringValue("docs"),
+						aliasArg:         command.StringListValue(""),
+					},
+				},
 			},
 		},
 		{
-			name: "toggles daemon mode to false",
+			name: "suggests aliases from the selected group for delete",
 			e: &Emacs{
-				DaemonMode: true,
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"salt": {path("compounds", "sodiumChloride")},
+					},
+					"docs": {
+						"readme": {"README.md"},
+						"notes":  {"notes.org"},
+					},
+				},
 			},
-			etc: &command.ExecuteTestCase{
-				Args:       []string{"dae"},
-				WantStdout: []string{"Daemon mode deactivated."},
+			ctc: &command.CompleteTestCase{
+				Args: []string{"-G", "docs", "d", "r"},
+				Want: []string{
+					"readme",
+				},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						groupFlag.Name(): command.StringValue("docs"),
+						aliasArg:         command.StringListValue("r"),
+					},
+				},
 			},
-			want: &Emacs{},
 		},
-		// OpenEditor tests
 		{
-			name: "error when too many arguments",
-			etc: &command.ExecuteTestCase{
-				Args:       []string{path("alpha.txt"), path("alpha.go"), path("file3")},
-				WantStderr: []string{fmt.Sprintf("Unprocessed extra args: [%s]", path("file3"))},
-				WantErr:    fmt.Errorf("Unprocessed extra args: [%s]", path("file3")),
-				WantData: &command.Data{
-					Values: map[string]*command.Value{
-						emacsArg: command.StringListValue(absPath(t, "alpha.txt"), absPath(t, "alpha.go")),
+			name: "completes group flag values",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"salt": {path("compounds", "sodiumChloride")},
 					},
-				},
-				WantExecuteData: &command.ExecuteData{
-					Executable: []string{
-						fmt.Sprintf("emacs --no-window-system %s %s", absPath(t, "alpha.go"), absPath(t, "alpha.txt")),
+					"docs": {
+						"readme": {"README.md"},
+					},
+					"dotfiles": {
+						"bashrc": {".bashrc"},
 					},
 				},
 			},
-		}, {
-			name: "cds into directory",
-			etc: &command.ExecuteTestCase{
-				Args: []string{path("dirA")},
-				WantExecuteData: &command.ExecuteData{
-					Executable: []string{fmt.Sprintf("cd %s", absPath(t, "dirA"))},
+			ctc: &command.CompleteTestCase{
+				Args: []string{"l", "--group", "do"},
+				Want: []string{
+					"docs",
+					"dotfiles",
 				},
 				WantData: &command.Data{
 					Values: map[string]*command.Value{
-						emacsArg: command.StringListValue(absPath(t, "dirA")),
+						groupFlag.Name(): command.StringValue("do"),
 					},
 				},
 			},
-			want: &Emacs{
-				Caches: map[string][]string{
-					cacheName: {absPath(t, "dirA")},
+		},
+		{
+			name: "completes all group flag values",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"salt": {path("compounds", "sodiumChloride")},
+					},
+					"docs": {
+						"readme": {"README.md"},
+					},
 				},
 			},
-		}, {
-			name: "fails if file does not exist and new flag not provided",
-			etc: &command.ExecuteTestCase{
-				Args: []string{path("newFile.txt")},
-				WantStderr: []string{
-					fmt.Sprintf(`file %q does not exist; include "new" flag to create it`, absPath(t, "newFile.txt")),
+			ctc: &command.CompleteTestCase{
+				Args: []string{"-G", ""},
+				Want: []string{
+					"docs",
+					fileAliaserName,
 				},
-				WantErr: fmt.Errorf(`file %q does not exist; include "new" flag to create it`, absPath(t, "newFile.txt")),
 				WantData: &command.Data{
 					Values: map[string]*command.Value{
-						emacsArg: command.StringListValue(absPath(t, "newFile.txt")),
+						groupFlag.Name(): command.StringValue(""),
 					},
 				},
 			},
-			want: &Emacs{
-				Caches: map[string][]string{
-					cacheName: {absPath(t, "newFile.txt")},
-				},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if test.e == nil {
+				test.e = e
+			}
+			test.ctc.Node = test.e.Node()
+			command.CompleteTest(t, test.ctc, nil)
+		})
+	}
+}
+
+func TestEmacsExecution(t *testing.T) {
+	for _, test := range []struct {
+		name  string
+		e     *Emacs
+		etc   *command.ExecuteTestCase
+		want  *Emacs
+		stdin string
+		// fileInfos overrides the os.Stat result for the provided files. Nil
+		// values are files that don't exist.
+		fileInfos map[string]os.FileInfo
+		// globs overrides the matches for the provided glob patterns.
+		globs map[string][]string
+		// env is the environment used when expanding file arguments.
+		env           map[string]string
+		wantClipboard []string
+		wantMkdirs    []string
+		wantCreates   []string
+		// now is the time used for new history entries.
+		now time.Time
+		// runs maps command prefixes to the output of running them.
+		runs map[string][]string
+	}{
+		// Daemon mode.
+		{
+			name: "daemon toggle doesn't accept args",
+			etc: &command.ExecuteTestCase{
+				Args:       []string{"dae", "mon"},
+				WantErr:    fmt.Errorf("Unprocessed extra args: [mon]"),
+				WantStderr: []string{"Unprocessed extra args: [mon]"},
 			},
-		}, {
-			name: "creates new file if short new flag is provided",
+		},
+		{
+			name: "toggles global daemon mode to true",
 			etc: &command.ExecuteTestCase{
-				Args: []string{path("newFile.txt"), "-n"},
+				Args:       []string{"dae", "--global"},
+				WantStdout: []string{"Daemon mode activated."},
 				WantData: &command.Data{
 					Values: map[string]*command.Value{
-						emacsArg:   command.StringListValue(absPath(t, "newFile.txt")),
-						newFileArg: command.BoolValue(true),
-					},
-				},
-				WantExecuteData: &command.ExecuteData{
-					Executable: []string{
-						fmt.Sprintf("emacs --no-window-system %s", absPath(t, "newFile.txt")),
+						globalFlag.Name(): command.BoolValue(true),
 					},
 				},
 			},
 			want: &Emacs{
-				Caches: map[string][]string{
-					cacheName: {
-						absPath(t, "newFile.txt"),
-						"-n",
-					},
-				},
+				DaemonMode: true,
+			},
+		},
+		{
+			name: "toggles global daemon mode to false",
+			e: &Emacs{
+				DaemonMode: true,
 			},
-		}, {
-			name: "creates new file if new flag is provided",
 			etc: &command.ExecuteTestCase{
-				Args: []string{path("newFile.txt"), "--new"},
-				WantExecuteData: &command.ExecuteData{
-					Executable: []string{
-						fmt.Sprintf("emacs --no-window-system %s", absPath(t, "newFile.txt")),
-					},
-				},
+				Args:       []string{"dae", "-g"},
+				WantStdout: []string{"Daemon mode deactivated."},
 				WantData: &command.Data{
 					Values: map[string]*command.Value{
-						emacsArg:   command.StringListValue(absPath(t, "newFile.txt")),
-						newFileArg: command.BoolValue(true),
+						globalFlag.Name(): command.BoolValue(true),
 					},
 				},
 			},
+			want: &Emacs{},
+		},
+		{
+			name: "toggles daemon mode for directory to true",
+			etc: &command.ExecuteTestCase{
+				Args:       []string{"dae"},
+				WantStdout: []string{fmt.Sprintf("Daemon mode activated in %s.", absPath(t))},
+			},
 			want: &Emacs{
-				Caches: map[string][]string{
-					cacheName: {
-						absPath(t, "newFile.txt"),
-						"--new",
-					},
+				DirDaemonMode: map[string]bool{
+					absPath(t): true,
 				},
 			},
-		}, {
-			name: "handles all aliases",
+		},
+		{
+			name: "toggles daemon mode for directory from global setting",
 			e: &Emacs{
-				Aliases: map[string]map[string][]string{
-					fileAliaserName: {
-						"salt": {path("compounds", "sodiumChloride")},
-						"city": {path("catan", "oreAndWheat")},
-					},
-				},
+				DaemonMode: true,
 			},
 			etc: &command.ExecuteTestCase{
-				Args: []string{"salt", "city"},
-				WantData: &command.Data{
-					Values: map[string]*command.Value{
-						emacsArg: command.StringListValue(absPath(t, "compounds", "sodiumChloride"), absPath(t, "catan", "oreAndWheat")),
-					},
+				Args:       []string{"dae"},
+				WantStdout: []string{fmt.Sprintf("Daemon mode deactivated in %s.", absPath(t))},
+			},
+			want: &Emacs{
+				DaemonMode: true,
+				DirDaemonMode: map[string]bool{
+					absPath(t): false,
 				},
-				WantExecuteData: &command.ExecuteData{
-					Executable: []string{
-						fmt.Sprintf("emacs --no-window-system %s %s", absPath(t, "catan", "oreAndWheat"), absPath(t, "compounds", "sodiumChloride")),
-					},
+			},
+		},
+		{
+			name: "toggles daemon mode for directory from parent directory setting",
+			e: &Emacs{
+				DirDaemonMode: map[string]bool{
+					filepath.Dir(absPath(t)): true,
 				},
 			},
+			etc: &command.ExecuteTestCase{
+				Args:       []string{"dae"},
+				WantStdout: []string{fmt.Sprintf("Daemon mode deactivated in %s.", absPath(t))},
+			},
 			want: &Emacs{
-				Aliases: map[string]map[string][]string{
-					fileAliaserName: {
-						"salt": {path("compounds", "sodiumChloride")},
-						"city": {path("catan", "oreAndWheat")},
-					},
+				DirDaemonMode: map[string]bool{
+					filepath.Dir(absPath(t)): true,
+					absPath(t):               false,
 				},
-				Caches: map[string][]string{cacheName: {
-					absPath(t, "compounds", "sodiumChloride"),
-					absPath(t, "catan", "oreAndWheat"),
-				}},
 			},
-		}, {
-			name: "handles line numbers",
+		},
+		{
+			name: "uses directory daemon mode when opening files",
 			e: &Emacs{
-				Aliases: map[string]map[string][]string{
-					fileAliaserName: {
-						"salt": {path("compounds", "sodiumChloride")},
-						"city": {path("catan", "oreAndWheat")},
-					},
+				DirDaemonMode: map[string]bool{
+					filepath.Dir(absPath(t)): true,
 				},
 			},
 			etc: &command.ExecuteTestCase{
-				Args: []string{path("alpha.txt"), "salt", "32"},
+				Args: []string{path("alpha.go")},
 				WantData: &command.Data{
 					Values: map[string]*command.Value{
-						emacsArg: command.StringListValue(
-							absPath(t, "alpha.txt"),
-							absPath(t, "compounds", "sodiumChloride"),
-						),
-						lineArg: command.IntListValue(0, 32),
+						emacsArg: command.StringListValue(absPath(t, "alpha.go")),
 					},
 				},
 				WantExecuteData: &command.ExecuteData{
 					Executable: []string{
-						fmt.Sprintf("emacs --no-window-system +32 %s %s", absPath(t, "compounds", "sodiumChloride"), absPath(t, "alpha.txt")),
+						fmt.Sprintf(`emacsclient -t -e '(progn (find-file "%s"))'`, absPath(t, "alpha.go")),
 					},
 				},
 			},
 			want: &Emacs{
-				Aliases: map[string]map[string][]string{fileAliaserName: {
-					"salt": {path("compounds", "sodiumChloride")},
-					"city": {path("catan", "oreAndWheat")},
-				},
+				DirDaemonMode: map[string]bool{
+					filepath.Dir(absPath(t)): true,
 				},
 				Caches: map[string][]string{
-					cacheName: {
-						absPath(t, "alpha.txt"),
-						absPath(t, "compounds", "sodiumChloride"),
-						"32",
-					},
+					cacheName: {absPath(t, "alpha.go")},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go")}, Args: []string{absPath(t, "alpha.go")}},
 				},
 			},
-		}, {
-			name: "handles multiple numbers with number filename",
+		},
+		{
+			name: "directory daemon mode overrides global daemon mode",
 			e: &Emacs{
-				Aliases: map[string]map[string][]string{
-					fileAliaserName: {
-						"salt": {path("compounds", "sodiumChloride")},
-						"city": {path("catan", "oreAndWheat")},
-					},
+				DaemonMode: true,
+				DirDaemonMode: map[string]bool{
+					absPath(t): false,
 				},
 			},
 			etc: &command.ExecuteTestCase{
-				Args: []string{"salt", "32", path("42")},
+				Args: []string{path("alpha.go")},
 				WantData: &command.Data{
 					Values: map[string]*command.Value{
-						emacsArg: command.StringListValue(absPath(t, "compounds", "sodiumChloride"), absPath(t, "42")),
-						lineArg:  command.IntListValue(32),
+						emacsArg: command.StringListValue(absPath(t, "alpha.go")),
 					},
 				},
 				WantExecuteData: &command.ExecuteData{
 					Executable: []string{
-						fmt.Sprintf("emacs --no-window-system %s +32 %s", absPath(t, "42"), absPath(t, "compounds", "sodiumChloride")),
+						fmt.Sprintf("emacs --no-window-system %s", absPath(t, "alpha.go")),
 					},
 				},
 			},
 			want: &Emacs{
-				Aliases: map[string]map[string][]string{fileAliaserName: {
-					"salt": {path("compounds", "sodiumChloride")},
-					"city": {path("catan", "oreAndWheat")},
-				},
+				DaemonMode: true,
+				DirDaemonMode: map[string]bool{
+					absPath(t): false,
 				},
 				Caches: map[string][]string{
-					cacheName: {
-						absPath(t, "compounds", "sodiumChloride"),
-						"32",
-						absPath(t, "42"),
-					},
+					cacheName: {absPath(t, "alpha.go")},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go")}, Args: []string{absPath(t, "alpha.go")}},
 				},
 			},
-		}, {
-			name: "adds to previous executions",
+		},
+		{
+			name: "toggles daemon chdir mode to true",
+			etc: &command.ExecuteTestCase{
+				Args:       []string{"settings", "dcd"},
+				WantStdout: []string{"Daemon file directories activated."},
+			},
+			want: &Emacs{
+				DaemonChdir: true,
+			},
+		},
+		{
+			name: "toggles daemon chdir mode to false",
 			e: &Emacs{
-				Aliases: map[string]map[string][]string{
-					fileAliaserName: {
-						"city": {path("catan", "oreAndWheat")},
-					},
-				},
-				Caches: map[string][]string{
-					cacheName: {
-						"firstFile",
-					},
-				},
+				DaemonChdir: true,
 			},
 			etc: &command.ExecuteTestCase{
-				Args: []string{path("luckyNumberThree")},
+				Args:       []string{"settings", "dcd"},
+				WantStdout: []string{"Daemon file directories deactivated."},
+			},
+			want: &Emacs{},
+		},
+		{
+			name: "sets emacs binary",
+			etc: &command.ExecuteTestCase{
+				Args: []string{"settings", "bin", path("alpha.go")},
 				WantData: &command.Data{
 					Values: map[string]*command.Value{
-						emacsArg: command.StringListValue(absPath(t, "luckyNumberThree")),
-					},
-				},
-				WantExecuteData: &command.ExecuteData{
-					Executable: []string{
-						fmt.Sprintf("emacs --no-window-system %s", absPath(t, "luckyNumberThree")),
+						binaryArg: command.StringValue(absPath(t, "alpha.go")),
 					},
 				},
+				WantStdout: []string{fmt.Sprintf("Emacs binary set to %s.", absPath(t, "alpha.go"))},
 			},
 			want: &Emacs{
-				Aliases: map[string]map[string][]string{fileAliaserName: {
-					"city": {path("catan", "oreAndWheat")},
-				},
-				},
-				Caches: map[string][]string{
-					cacheName: {
-						absPath(t, "luckyNumberThree"),
-					},
-				},
+				Binary: absPath(t, "alpha.go"),
 			},
-		}, {
-			name: "reduces size of previous executions if at limit",
+		},
+		{
+			name: "resets emacs binary",
 			e: &Emacs{
-				Aliases: map[string]map[string][]string{
-					fileAliaserName: {
-						"city": {path("catan", "oreAndWheat")},
+				Binary: absPath(t, "alpha.go"),
+			},
+			etc: &command.ExecuteTestCase{
+				Args:       []string{"settings", "bin"},
+				WantStdout: []string{"Emacs binary reset to default."},
+			},
+			want: &Emacs{},
+		},
+		{
+			name: "fails to set emacs binary to a directory",
+			etc: &command.ExecuteTestCase{
+				Args: []string{"settings", "bin", path("dirA")},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						binaryArg: command.StringValue(absPath(t, "dirA")),
 					},
 				},
-				Caches: map[string][]string{
-					cacheName: {
-						"firstFile",
+				WantStderr: []string{fmt.Sprintf("%q is a directory", absPath(t, "dirA"))},
+				WantErr:    fmt.Errorf("%q is a directory", absPath(t, "dirA")),
+			},
+		},
+		{
+			name: "sets project root",
+			etc: &command.ExecuteTestCase{
+				Args: []string{"settings", "root", path("nested")},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						rootArg: command.StringValue(absPath(t, "nested")),
 					},
 				},
+				WantStdout: []string{fmt.Sprintf("Project root set to %s.", absPath(t, "nested"))},
+			},
+			want: &Emacs{
+				Root: absPath(t, "nested"),
+			},
+		},
+		{
+			name: "resets project root",
+			e: &Emacs{
+				Root: absPath(t, "nested"),
 			},
 			etc: &command.ExecuteTestCase{
-				Args: []string{path("luckyNumberThree")},
+				Args:       []string{"settings", "root"},
+				WantStdout: []string{"Project root reset to current directory."},
+			},
+			want: &Emacs{},
+		},
+		{
+			name: "fails to set project root to a file",
+			etc: &command.ExecuteTestCase{
+				Args: []string{"settings", "root", path("alpha.go")},
 				WantData: &command.Data{
 					Values: map[string]*command.Value{
-						emacsArg: command.StringListValue(absPath(t, "luckyNumberThree")),
+						rootArg: command.StringValue(absPath(t, "alpha.go")),
+					},
+				},
+				WantStderr: []string{fmt.Sprintf("%q is not a directory", absPath(t, "alpha.go"))},
+				WantErr:    fmt.Errorf("%q is not a directory", absPath(t, "alpha.go")),
+			},
+		},
+		{
+			name: "resolves relative files against project root",
+			e: &Emacs{
+				Root: absPath(t, "nested"),
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"top.txt", absPath(t, "alpha.go")},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue(absPath(t, "nested", "top.txt"), absPath(t, "alpha.go")),
 					},
 				},
 				WantExecuteData: &command.ExecuteData{
 					Executable: []string{
-						fmt.Sprintf("emacs --no-window-system %s", absPath(t, "luckyNumberThree")),
+						fmt.Sprintf("emacs --no-window-system %s %s %s", absPath(t, "alpha.go"), absPath(t, "nested", "top.txt"), splitEval(absPath(t, "alpha.go"))),
 					},
 				},
 			},
 			want: &Emacs{
-				Aliases: map[string]map[string][]string{fileAliaserName: {
-					"city": {path("catan", "oreAndWheat")},
-				}},
+				Root: absPath(t, "nested"),
 				Caches: map[string][]string{
-					cacheName: {
-						absPath(t, "luckyNumberThree"),
-					},
+					cacheName: {absPath(t, "nested", "top.txt"), absPath(t, "alpha.go")},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "nested", "top.txt"), absPath(t, "alpha.go")}, Args: []string{absPath(t, "nested", "top.txt"), absPath(t, "alpha.go")}},
 				},
 			},
-		}, {
-			name: "if empty cache and no arguments, error",
+		},
+		{
+			name: "uses configured emacs binary",
+			e: &Emacs{
+				Binary: "/opt/homebrew/bin/emacs",
+			},
 			etc: &command.ExecuteTestCase{
-				WantErr:    fmt.Errorf("not enough arguments"),
-				WantStderr: []string{"not enough arguments"},
+				Args: []string{path("alpha.go")},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue(absPath(t, "alpha.go")),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("/opt/homebrew/bin/emacs --no-window-system %s", absPath(t, "alpha.go")),
+					},
+				},
 			},
-		}, {
-			name: "if nil argument, run last command",
-			e: &Emacs{
-				Aliases: map[string]map[string][]string{
-					fileAliaserName: {
-						"city": {path("catan", "oreAndWheat")},
-					}},
+			want: &Emacs{
+				Binary: "/opt/homebrew/bin/emacs",
 				Caches: map[string][]string{
-					cacheName: {
-						absPath(t, "alpha.go"),
-					},
+					cacheName: {absPath(t, "alpha.go")},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go")}, Args: []string{absPath(t, "alpha.go")}},
 				},
 			},
+		},
+		{
+			name: "checks daemon status",
 			etc: &command.ExecuteTestCase{
+				Args: []string{"dstat"},
 				WantExecuteData: &command.ExecuteData{
 					Executable: []string{
-						fmt.Sprintf("emacs --no-window-system %s", absPath(t, "alpha.go")),
+						"if emacsclient -e '(server-running-p)' > /dev/null 2>&1; then echo Emacs daemon is running; else echo Emacs daemon is not running; fi",
 					},
 				},
+			},
+		},
+		{
+			name: "deactivates case-insensitive completion",
+			etc: &command.ExecuteTestCase{
+				Args: []string{"settings", "nocase", "off"},
 				WantData: &command.Data{
 					Values: map[string]*command.Value{
-						emacsArg: command.StringListValue(absPath(t, "alpha.go")),
+						nocaseArg: command.StringValue("off"),
 					},
 				},
+				WantStdout: []string{"Case-insensitive completion deactivated."},
 			},
-		}, {
-			name: "if empty arguments, run last command",
+			want: &Emacs{
+				CaseSensitive: true,
+			},
+		},
+		{
+			name: "activates case-insensitive completion",
 			e: &Emacs{
-				Aliases: map[string]map[string][]string{
-					fileAliaserName: {
-						"city": {path("catan", "oreAndWheat")},
-					}},
-				Caches: map[string][]string{
-					cacheName: {
-						absPath(t, "alpha.go"),
+				CaseSensitive: true,
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"settings", "nocase", "on"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						nocaseArg: command.StringValue("on"),
 					},
 				},
+				WantStdout: []string{"Case-insensitive completion activated."},
 			},
+			want: &Emacs{},
+		},
+		{
+			name: "activates gui mode",
 			etc: &command.ExecuteTestCase{
-				Args: []string{},
-				WantExecuteData: &command.ExecuteData{
-					Executable: []string{
-						fmt.Sprintf("emacs --no-window-system %s", absPath(t, "alpha.go")),
+				Args: []string{"settings", "gui", "on"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						guiArg: command.StringValue("on"),
 					},
 				},
+				WantStdout: []string{"GUI mode activated."},
+			},
+			want: &Emacs{
+				GUI: true,
+			},
+		},
+		{
+			name: "deactivates gui mode",
+			e: &Emacs{
+				GUI: true,
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"settings", "gui", "off"},
 				WantData: &command.Data{
 					Values: map[string]*command.Value{
-						emacsArg: command.StringListValue(absPath(t, "alpha.go")),
+						guiArg: command.StringValue("off"),
 					},
 				},
+				WantStdout: []string{"GUI mode deactivated."},
 			},
-		}, // AddAlias tests
+			want: &Emacs{},
+		},
 		{
-			name: "fails if no alias",
+			name: "settings requires a setting",
 			etc: &command.ExecuteTestCase{
-				Args:       []string{"a"},
-				WantStderr: []string{"not enough arguments"},
-				WantErr:    fmt.Errorf("not enough arguments"),
+				Args:       []string{"settings"},
+				WantStderr: []string{"branching argument required"},
+				WantErr:    fmt.Errorf("branching argument required"),
 			},
 		},
 		{
-			name: "handles more than one arguments",
+			name: "activates case-insensitive aliases",
 			etc: &command.ExecuteTestCase{
-				Args: []string{"a", "duo", path("alpha.go"), path("alpha.txt")},
+				Args: []string{"settings", "aliasnocase", "on"},
 				WantData: &command.Data{
 					Values: map[string]*command.Value{
-						"ALIAS":  command.StringValue("duo"),
-						emacsArg: command.StringListValue(absPath(t, "alpha.go"), absPath(t, "alpha.txt")),
+						aliasCaseArg: command.StringValue("on"),
 					},
 				},
+				WantStdout: []string{"Case-insensitive aliases activated."},
 			},
 			want: &Emacs{
-				Aliases: map[string]map[string][]string{
-					fileAliaserName: {
-						"duo": []string{absPath(t, "alpha.go"), absPath(t, "alpha.txt")},
-					},
-				},
-				Caches: map[string][]string{
-					cacheName: {absPath(t, "alpha.go"), absPath(t, "alpha.txt")},
-				},
+				CaseInsensitiveAliases: true,
 			},
-		}, {
-			name: "fails if alias already defined",
+		},
+		{
+			name: "deactivates case-insensitive aliases",
 			e: &Emacs{
-				Aliases: map[string]map[string][]string{
-					fileAliaserName: {
-						"salt": {"NaCl"},
-					}},
+				CaseInsensitiveAliases: true,
 			},
 			etc: &command.ExecuteTestCase{
-				Args: []string{"a", "salt", "sodiumChloride"},
+				Args: []string{"settings", "aliasnocase", "off"},
 				WantData: &command.Data{
 					Values: map[string]*command.Value{
-						"ALIAS": command.StringValue("salt"),
+						aliasCaseArg: command.StringValue("off"),
 					},
 				},
-				WantStderr: []string{`Alias "salt" already exists`},
-				WantErr:    fmt.Errorf(`Alias "salt" already exists`),
+				WantStdout: []string{"Case-insensitive aliases deactivated."},
 			},
-		}, {
-			name: "fails if file does not exist",
+			want: &Emacs{},
+		},
+		{
+			name: "gui requires on or off",
 			etc: &command.ExecuteTestCase{
-				Args: []string{"a", "nf", path("newFile.txt")},
+				Args: []string{"settings", "gui", "maybe"},
 				WantData: &command.Data{
 					Values: map[string]*command.Value{
-						"ALIAS":  command.StringValue("nf"),
-						emacsArg: command.StringListValue(absPath(t, "newFile.txt")),
+						guiArg: command.StringValue("maybe"),
 					},
 				},
-				WantErr:    fmt.Errorf(`file %q does not exist; include "new" flag to create it`, absPath(t, "newFile.txt")),
-				WantStderr: []string{fmt.Sprintf(`file %q does not exist; include "new" flag to create it`, absPath(t, "newFile.txt"))},
+				WantStderr: []string{`validation failed: value must be "on" or "off"`},
+				WantErr:    fmt.Errorf(`validation failed: value must be "on" or "off"`),
 			},
-			want: &Emacs{
-				Caches: map[string][]string{
-					cacheName: {absPath(t, "newFile.txt")},
-				},
+		},
+		{
+			name: "opens files in a window in gui mode",
+			e: &Emacs{
+				GUI: true,
 			},
-		}, {
-			name: "adds to nil aliases",
 			etc: &command.ExecuteTestCase{
-				Args: []string{"a", "uno", path("alpha.go")},
+				Args: []string{path("alpha.go"), "-m", "1"},
 				WantData: &command.Data{
 					Values: map[string]*command.Value{
-						"ALIAS":  command.StringValue("uno"),
-						emacsArg: command.StringListValue(absPath(t, "alpha.go")),
+						emacsArg:           command.StringListValue(absPath(t, "alpha.go")),
+						monitorFlag.Name(): command.IntValue(1),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --eval '%s' %s", monitorElisp(1), absPath(t, "alpha.go")),
 					},
 				},
 			},
 			want: &Emacs{
-				Aliases: map[string]map[string][]string{fileAliaserName: {
-					"uno": {absPath(t, "alpha.go")},
-				}},
+				GUI: true,
 				Caches: map[string][]string{
-					cacheName: {absPath(t, "alpha.go")},
+					cacheName: {absPath(t, "alpha.go"), "-m", "1"},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go")}, Args: []string{absPath(t, "alpha.go"), "-m", "1"}},
 				},
 			},
-		}, {
-			name: "adds to empty aliases",
+		},
+		{
+			name: "adds extra flags",
 			e: &Emacs{
-				Aliases: map[string]map[string][]string{
-					fileAliaserName: {},
-				},
+				ExtraFlags: []string{"--no-splash"},
 			},
 			etc: &command.ExecuteTestCase{
-				Args: []string{"a", "uno", path("alpha.txt")},
+				Args: []string{"settings", "flags", "--fullscreen", "--reverse-video"},
 				WantData: &command.Data{
 					Values: map[string]*command.Value{
-						"ALIAS":  command.StringValue("uno"),
-						emacsArg: command.StringListValue(absPath(t, "alpha.txt")),
+						extraFlagsArg: command.StringListValue("--fullscreen", "--reverse-video"),
 					},
 				},
+				WantStdout: []string{"Extra flags: --no-splash --fullscreen --reverse-video"},
 			},
 			want: &Emacs{
-				Aliases: map[string]map[string][]string{fileAliaserName: {
-					"uno": {absPath(t, "alpha.txt")},
-				}},
-				Caches: map[string][]string{
-					cacheName: {absPath(t, "alpha.txt")},
-				},
+				ExtraFlags: []string{"--no-splash", "--fullscreen", "--reverse-video"},
 			},
-		}, {
-			name: "adds to aliases",
+		},
+		{
+			name: "lists extra flags",
 			e: &Emacs{
-				Aliases: map[string]map[string][]string{
-					fileAliaserName: {
-						"other": {path("things")},
-						"ab":    {path("cd")},
+				ExtraFlags: []string{"--no-splash", "--fullscreen", `--eval=(message (format "%s" 1))`},
+			},
+			etc: &command.ExecuteTestCase{
+				Args:       []string{"settings", "flags"},
+				WantStdout: []string{"--no-splash", "--fullscreen", `--eval=(message (format "%s" 1))`},
+			},
+		},
+		{
+			name: "clears extra flags",
+			e: &Emacs{
+				ExtraFlags: []string{"--no-splash"},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"settings", "flags", "--clear"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						clearFlag.Name(): command.BoolValue(true),
 					},
 				},
+				WantStdout: []string{"Extra flags cleared."},
+			},
+			want: &Emacs{},
+		},
+		{
+			name: "passes extra flags in basic mode",
+			e: &Emacs{
+				ExtraFlags: []string{"--no-splash"},
 			},
 			etc: &command.ExecuteTestCase{
-				Args: []string{"a", "un", path("alpha.go")},
+				Args: []string{path("alpha.go")},
 				WantData: &command.Data{
 					Values: map[string]*command.Value{
-						"ALIAS":  command.StringValue("un"),
 						emacsArg: command.StringListValue(absPath(t, "alpha.go")),
 					},
 				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system --no-splash %s", absPath(t, "alpha.go")),
+					},
+				},
 			},
 			want: &Emacs{
-				Aliases: map[string]map[string][]string{fileAliaserName: {
-					"other": {path("things")},
-					"ab":    {path("cd")},
-					"un":    {absPath(t, "alpha.go")},
-				}},
+				ExtraFlags: []string{"--no-splash"},
 				Caches: map[string][]string{
 					cacheName: {absPath(t, "alpha.go")},
 				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go")}, Args: []string{absPath(t, "alpha.go")}},
+				},
+			},
+		},
+		{
+			name: "doesn't pass extra flags in daemon mode",
+			e: &Emacs{
+				DaemonMode: true,
+				ExtraFlags: []string{"--no-splash"},
 			},
-		}, {
-			name: "adds alias for directory",
 			etc: &command.ExecuteTestCase{
-				Args: []string{"a", "t", path("dirA")},
+				Args: []string{path("alpha.go")},
 				WantData: &command.Data{
 					Values: map[string]*command.Value{
-						"ALIAS":  command.StringValue("t"),
-						emacsArg: command.StringListValue(absPath(t, "dirA")),
+						emacsArg: command.StringListValue(absPath(t, "alpha.go")),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf(`emacsclient -t -e '(progn (find-file "%s"))'`, absPath(t, "alpha.go")),
 					},
 				},
 			},
 			want: &Emacs{
-				Aliases: map[string]map[string][]string{fileAliaserName: {
-					"t": {absPath(t, "dirA")},
-				}},
+				DaemonMode: true,
+				ExtraFlags: []string{"--no-splash"},
 				Caches: map[string][]string{
-					cacheName: {absPath(t, "dirA")},
+					cacheName: {absPath(t, "alpha.go")},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go")}, Args: []string{absPath(t, "alpha.go")}},
 				},
 			},
-		}, // DeleteAliases tests
+		},
 		{
-			name: "error if no arguments",
-			etc: &command.ExecuteTestCase{
-				Args:       []string{"d"},
-				WantStderr: []string{"not enough arguments"},
-				WantErr:    fmt.Errorf("not enough arguments"),
-			},
-		}, {
-			name: "ignores unknown alias",
+			name: "activates automatic daemon detection",
 			etc: &command.ExecuteTestCase{
-				Args: []string{"d", "salt"},
+				Args: []string{"settings", "auto", "on"},
 				WantData: &command.Data{
 					Values: map[string]*command.Value{
-						"ALIAS": command.StringListValue("salt"),
+						autoArg: command.StringValue("on"),
 					},
 				},
-				WantStderr: []string{`Alias group has no aliases yet.`},
-				WantErr:    fmt.Errorf(`Alias group has no aliases yet.`),
+				WantStdout: []string{"Automatic daemon detection activated."},
 			},
-		}, {
-			name: "deletes existing alias",
+			want: &Emacs{
+				AutoDaemon: true,
+			},
+		},
+		{
+			name: "deactivates automatic daemon detection",
 			e: &Emacs{
-				Aliases: map[string]map[string][]string{
-					fileAliaserName: {
-						"salt": {path("compounds", "sodiumChloride")},
+				AutoDaemon: true,
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"settings", "auto", "off"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						autoArg: command.StringValue("off"),
 					},
 				},
+				WantStdout: []string{"Automatic daemon detection deactivated."},
+			},
+			want: &Emacs{},
+		},
+		{
+			name: "uses daemon if its socket exists",
+			e: &Emacs{
+				AutoDaemon: true,
+			},
+			env: map[string]string{
+				"XDG_RUNTIME_DIR": "/run/user/1000",
+			},
+			fileInfos: map[string]os.FileInfo{
+				"/run/user/1000/emacs/server": fakeFileInfo{mode: os.ModeSocket},
 			},
 			etc: &command.ExecuteTestCase{
-				Args: []string{"d", "salt"},
+				Args: []string{path("alpha.go")},
 				WantData: &command.Data{
 					Values: map[string]*command.Value{
-						"ALIAS": command.StringListValue("salt"),
+						emacsArg: command.StringListValue(absPath(t, "alpha.go")),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf(`emacsclient -t -e '(progn (find-file "%s"))'`, absPath(t, "alpha.go")),
 					},
 				},
 			},
 			want: &Emacs{
-				Aliases: map[string]map[string][]string{fileAliaserName: {}},
+				AutoDaemon: true,
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go")},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go")}, Args: []string{absPath(t, "alpha.go")}},
+				},
 			},
-		}, {
-			name: "handles multiple missing and present",
+		},
+		{
+			name: "uses basic mode if the daemon socket doesn't exist",
 			e: &Emacs{
-				Aliases: map[string]map[string][]string{
-					fileAliaserName: {
-						"salt": {path("compounds", "sodiumChloride")},
-						"city": {path("catan", "oreAndWheat")},
-						"4":    {"2+2"},
-					},
-				},
+				AutoDaemon: true,
+				DaemonMode: true,
+				Socket:     "work",
+			},
+			env: map[string]string{
+				"XDG_RUNTIME_DIR": "/run/user/1000",
+			},
+			fileInfos: map[string]os.FileInfo{
+				"/run/user/1000/emacs/server": fakeFileInfo{mode: os.ModeSocket},
 			},
 			etc: &command.ExecuteTestCase{
-				Args: []string{"d", "salt", "settlement", "5", "4"},
+				Args: []string{path("alpha.go")},
 				WantData: &command.Data{
 					Values: map[string]*command.Value{
-						"ALIAS": command.StringListValue("salt", "settlement", "5", "4"),
+						emacsArg: command.StringListValue(absPath(t, "alpha.go")),
 					},
 				},
-				WantStderr: []string{
-					`Alias "settlement" does not exist`,
-					`Alias "5" does not exist`,
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system %s", absPath(t, "alpha.go")),
+					},
 				},
 			},
 			want: &Emacs{
-				Aliases: map[string]map[string][]string{fileAliaserName: {
-					"city": {path("catan", "oreAndWheat")},
-				}},
+				AutoDaemon: true,
+				DaemonMode: true,
+				Socket:     "work",
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go")},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go")}, Args: []string{absPath(t, "alpha.go")}},
+				},
 			},
-		}, // ListAliases tests
+		},
 		{
-			name: "error when too many arguments for list",
+			name: "sets normal file order",
 			etc: &command.ExecuteTestCase{
-				Args:       []string{"l", "extra"},
-				WantStderr: []string{"Unprocessed extra args: [extra]"},
-				WantErr:    fmt.Errorf("Unprocessed extra args: [extra]"),
+				Args: []string{"settings", "order", "normal"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						orderArg: command.StringValue("normal"),
+					},
+				},
+				WantStdout: []string{"Files will be opened in normal order."},
 			},
-		}, {
-			name: "no output for nil aliases",
-			etc: &command.ExecuteTestCase{
-				Args: []string{"l"},
+			want: &Emacs{
+				NormalOrder: true,
 			},
-		}, {
-			name: "no output for empty aliases",
+		},
+		{
+			name: "sets reverse file order",
 			e: &Emacs{
-				Aliases: map[string]map[string][]string{
-					fileAliaserName: {},
-				},
+				NormalOrder: true,
 			},
 			etc: &command.ExecuteTestCase{
-				Args: []string{"l"},
-			},
-		}, {
-			name: "proper output for aliases",
-			e: &Emacs{
-				Aliases: map[string]map[string][]string{
-					fileAliaserName: {
-						"salt": {"compounds/sodiumChloride"},
-						"city": {"catan", "oreAndWheat"},
-						"4":    {"2+2"},
+				Args: []string{"settings", "order", "reverse"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						orderArg: command.StringValue("reverse"),
 					},
 				},
+				WantStdout: []string{"Files will be opened in reverse order."},
 			},
+			want: &Emacs{},
+		},
+		{
+			name: "order requires normal or reverse",
 			etc: &command.ExecuteTestCase{
-				Args: []string{"l"},
-				WantStdout: []string{
-					"4: 2+2",
-					"city: catan oreAndWheat",
-					"salt: compounds/sodiumChloride",
+				Args: []string{"settings", "order", "sideways"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						orderArg: command.StringValue("sideways"),
+					},
 				},
+				WantStderr: []string{`validation failed: value must be "normal" or "reverse"`},
+				WantErr:    fmt.Errorf(`validation failed: value must be "normal" or "reverse"`),
 			},
-		}, // GetAlias
+		},
 		{
-			name: "GetAlias requires alias",
-			etc: &command.ExecuteTestCase{
-				Args: []string{"g"},
-				WantStderr: []string{
-					fmt.Sprintf("not enough arguments"),
-				},
-				WantErr: fmt.Errorf("not enough arguments"),
+			name: "opens files in normal order",
+			e: &Emacs{
+				NormalOrder: true,
 			},
-		}, {
-			name: "GetAlias fails if alias group does not exist",
 			etc: &command.ExecuteTestCase{
-				Args: []string{"g", "salt"},
+				Args: []string{path("alpha.go"), path("alpha.txt")},
 				WantData: &command.Data{
 					Values: map[string]*command.Value{
-						"ALIAS": command.StringListValue("salt"),
+						emacsArg: command.StringListValue(absPath(t, "alpha.go"), absPath(t, "alpha.txt")),
 					},
 				},
-				WantStderr: []string{
-					`No aliases exist for alias type "fileAliases"`,
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system %s %s %s", absPath(t, "alpha.go"), absPath(t, "alpha.txt"), splitEval(absPath(t, "alpha.go"))),
+					},
 				},
-				WantErr: fmt.Errorf(`No aliases exist for alias type "fileAliases"`),
 			},
-		}, {
-			name: "GetAlias fails if alias does not exist",
-			e: &Emacs{
-				Aliases: map[string]map[string][]string{
-					fileAliaserName: {
-						"ot": []string{"h", "e", "r"},
+			want: &Emacs{
+				NormalOrder: true,
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go"), absPath(t, "alpha.txt")},
+				},
+				History: []*historyEntry{
+					{
+						Files: []string{absPath(t, "alpha.go"), absPath(t, "alpha.txt")},
+						Args:  []string{absPath(t, "alpha.go"), absPath(t, "alpha.txt")},
 					},
 				},
 			},
+		},
+		{
+			name: "opens files in normal order in daemon mode",
+			e: &Emacs{
+				NormalOrder: true,
+				DaemonMode:  true,
+			},
 			etc: &command.ExecuteTestCase{
-				Args: []string{"g", "salt"},
+				Args: []string{path("alpha.go"), path("alpha.txt")},
 				WantData: &command.Data{
 					Values: map[string]*command.Value{
-						"ALIAS": command.StringListValue("salt"),
+						emacsArg: command.StringListValue(absPath(t, "alpha.go"), absPath(t, "alpha.txt")),
 					},
 				},
-				WantStderr: []string{
-					`Alias "salt" does not exist`,
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf(`emacsclient -t -e '(progn (find-file "%s")(find-file-other-window "%s")(other-window 1))'`, absPath(t, "alpha.txt"), absPath(t, "alpha.go")),
+					},
 				},
 			},
-		}, {
-			name: "GetAlias works",
-			e: &Emacs{
-				Aliases: map[string]map[string][]string{
-					fileAliaserName: {
-						"salt": {"compounds/sodiumChloride"},
-						"city": {"catan/oreAndWheat"},
-						"4":    {"2+2"},
-					}},
-			},
-			etc: &command.ExecuteTestCase{
-				Args: []string{"g", "salt"},
-				WantStdout: []string{
-					"salt: compounds/sodiumChloride",
+			want: &Emacs{
+				NormalOrder: true,
+				DaemonMode:  true,
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go"), absPath(t, "alpha.txt")},
 				},
-				WantData: &command.Data{
-					Values: map[string]*command.Value{
-						"ALIAS": command.StringListValue("salt"),
+				History: []*historyEntry{
+					{
+						Files: []string{absPath(t, "alpha.go"), absPath(t, "alpha.txt")},
+						Args:  []string{absPath(t, "alpha.go"), absPath(t, "alpha.txt")},
 					},
 				},
 			},
-		}, // SearchAliases
+		},
 		{
-			name: "SearchAlias requires regexp",
+			name: "nocase requires on or off",
 			etc: &command.ExecuteTestCase{
-				Args: []string{"s"},
-				WantStderr: []string{
-					fmt.Sprintf("not enough arguments"),
+				Args: []string{"settings", "nocase", "maybe"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						nocaseArg: command.StringValue("maybe"),
+					},
 				},
-				WantErr: fmt.Errorf("not enough arguments"),
+				WantStderr: []string{`validation failed: value must be "on" or "off"`},
+				WantErr:    fmt.Errorf(`validation failed: value must be "on" or "off"`),
 			},
-		}, {
-			name: "SearchAlias requires valid regexp",
+		},
+		{
+			name: "sets daemon socket",
 			etc: &command.ExecuteTestCase{
-				Args: []string{"s", "[a-9]"},
-				WantStderr: []string{
-					"Invalid regexp: error parsing regexp: invalid character class range: `a-9`",
-				},
+				Args: []string{"settings", "socket", "work"},
 				WantData: &command.Data{
 					Values: map[string]*command.Value{
-						"regexp": command.StringListValue("[a-9]"),
+						socketArg: command.StringValue("work"),
 					},
 				},
-				WantErr: fmt.Errorf("Invalid regexp: error parsing regexp: invalid character class range: `a-9`"),
+				WantStdout: []string{"Daemon socket set to work."},
 			},
-		}, {
-			name: "SearchAlias works",
+			want: &Emacs{
+				Socket: "work",
+			},
+		},
+		{
+			name: "resets daemon socket",
 			e: &Emacs{
-				Aliases: map[string]map[string][]string{
-					fileAliaserName: {
-						"water": {"liquids/compounds/hydrogenDioxide"},
-						"salt":  {"compounds/sodiumChloride"},
-						"city":  {"catan/oreAndWheat"},
-						"4":     {"2+2"},
+				Socket: "work",
+			},
+			etc: &command.ExecuteTestCase{
+				Args:       []string{"settings", "socket"},
+				WantStdout: []string{"Daemon socket reset to default."},
+			},
+			want: &Emacs{},
+		},
+		{
+			name: "starts daemon with socket",
+			e: &Emacs{
+				Socket: "work",
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"ds"},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						"echo Starting emacs daemon",
+						"emacs --daemon=work",
+						"echo Success!",
 					},
 				},
 			},
+		},
+		{
+			name: "does not start daemon if it is already running",
+			runs: map[string][]string{
+				"emacsclient -e '(server-running-p)'": {"t", ""},
+			},
 			etc: &command.ExecuteTestCase{
-				Args: []string{"s", "compounds"},
-				WantData: &command.Data{
-					Values: map[string]*command.Value{
-						"regexp": command.StringListValue("compounds"),
+				Args:       []string{"ds"},
+				WantStdout: []string{"Daemon already running."},
+			},
+		},
+		{
+			name: "does not start daemon with socket if it is already running",
+			e: &Emacs{
+				Socket: "work",
+			},
+			runs: map[string][]string{
+				"emacsclient -s work -e '(server-running-p)'": {"t", ""},
+			},
+			etc: &command.ExecuteTestCase{
+				Args:       []string{"ds"},
+				WantStdout: []string{"Daemon already running."},
+			},
+		},
+		{
+			name: "kills daemon with socket",
+			e: &Emacs{
+				Socket: "work",
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"dk"},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						"echo Killing emacs daemon",
+						"emacsclient -s work -e '(kill-emacs)'",
+						"echo Success!",
 					},
 				},
-				WantStdout: []string{
-					"salt: compounds/sodiumChloride",
-					"water: liquids/compounds/hydrogenDioxide",
-				},
+			},
+		},
+		{
+			name: "opens files with daemon socket",
+			e: &Emacs{
+				DaemonMode: true,
+				Socket:     "work",
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("alpha.go")},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue(absPath(t, "alpha.go")),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf(`emacsclient -s work -t -e '(progn (find-file "%s"))'`, absPath(t, "alpha.go")),
+					},
+				},
+			},
+			want: &Emacs{
+				DaemonMode: true,
+				Socket:     "work",
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go")},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go")}, Args: []string{absPath(t, "alpha.go")}},
+				},
+			},
+		},
+		{
+			name: "daemon chdir sets default-directory for each file",
+			e: &Emacs{
+				DaemonMode:  true,
+				DaemonChdir: true,
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("alpha.go"), path("compounds", "sodiumChloride")},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue(absPath(t, "alpha.go"), absPath(t, "compounds", "sodiumChloride")),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf(`emacsclient -t -e '(progn (let ((default-directory "%s/")) (find-file "%s"))(let ((default-directory "%s/")) (find-file-other-window "%s"))(other-window 1))'`, absPath(t), absPath(t, "alpha.go"), absPath(t, "compounds"), absPath(t, "compounds", "sodiumChloride")),
+					},
+				},
+			},
+			want: &Emacs{
+				DaemonMode:  true,
+				DaemonChdir: true,
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go"), absPath(t, "compounds", "sodiumChloride")},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go"), absPath(t, "compounds", "sodiumChloride")}, Args: []string{absPath(t, "alpha.go"), absPath(t, "compounds", "sodiumChloride")}},
+				},
+			},
+		},
+		// OpenEditor tests
+		{
+			name: "error when too many arguments",
+			etc: &command.ExecuteTestCase{
+				Args:       []string{path("alpha.txt"), path("alpha.go"), path("file3")},
+				WantStderr: []string{fmt.Sprintf("Unprocessed extra args: [%s]", path("file3"))},
+				WantErr:    fmt.Errorf("Unprocessed extra args: [%s]", path("file3")),
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue(absPath(t, "alpha.txt"), absPath(t, "alpha.go")),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system %s %s %s", absPath(t, "alpha.go"), absPath(t, "alpha.txt"), splitEval(absPath(t, "alpha.go"))),
+					},
+				},
+			},
+		}, {
+			name: "cds into symlinked directory",
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("catanLink")},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{fmt.Sprintf("cd %s", absPath(t, "catanLink"))},
+				},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue(absPath(t, "catanLink")),
+					},
+				},
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "catanLink")},
+				},
+			},
+		}, {
+			name: "cds into symlinked directory with trailing slash",
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("catanLink") + "/"},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{fmt.Sprintf("cd %s", absPath(t, "catanLink"))},
+				},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue(absPath(t, "catanLink")),
+					},
+				},
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "catanLink")},
+				},
+			},
+		}, {
+			name: "eval flag fails when cd-ing into directory",
+			etc: &command.ExecuteTestCase{
+				Args:       []string{path("catan"), "--eval", "(flymake-mode)"},
+				WantStderr: []string{`"eval" flag can't be used when no files are opened`},
+				WantErr:    fmt.Errorf(`"eval" flag can't be used when no files are opened`),
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue(absPath(t, "catan")),
+						evalArg:  command.StringListValue("(flymake-mode)"),
+					},
+				},
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "catan"), "--eval", "(flymake-mode)"},
+				},
+			},
+		}, {
+			name: "blame flag annotates file",
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("alpha.go"), "42", "--blame"},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system +42 %s --eval '(vc-annotate (buffer-file-name) (vc-working-revision (buffer-file-name)))'", absPath(t, "alpha.go")),
+					},
+				},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg:         command.StringListValue(absPath(t, "alpha.go")),
+						lineArg:          command.IntListValue(42),
+						blameFlag.Name(): command.BoolValue(true),
+					},
+				},
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go"), "42", "--blame"},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go")}, Args: []string{absPath(t, "alpha.go"), "42", "--blame"}},
+				},
+			},
+		}, {
+			name: "blame flag annotates file in daemon mode",
+			e: &Emacs{
+				DaemonMode: true,
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("alpha.go"), "42", "-b"},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf(`emacsclient -t -e '(progn (find-file "%s")(goto-line 42)(vc-annotate (buffer-file-name) (vc-working-revision (buffer-file-name))))'`, absPath(t, "alpha.go")),
+					},
+				},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg:         command.StringListValue(absPath(t, "alpha.go")),
+						lineArg:          command.IntListValue(42),
+						blameFlag.Name(): command.BoolValue(true),
+					},
+				},
+			},
+			want: &Emacs{
+				DaemonMode: true,
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go"), "42", "-b"},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go")}, Args: []string{absPath(t, "alpha.go"), "42", "-b"}},
+				},
+			},
+		}, {
+			name: "blame flag is ignored without a line number",
+			etc: &command.ExecuteTestCase{
+				Args:       []string{path("alpha.go"), "--blame"},
+				WantStderr: []string{`ignoring "blame" flag since no line number was provided`},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system %s", absPath(t, "alpha.go")),
+					},
+				},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg:         command.StringListValue(absPath(t, "alpha.go")),
+						blameFlag.Name(): command.BoolValue(true),
+					},
+				},
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go"), "--blame"},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go")}, Args: []string{absPath(t, "alpha.go"), "--blame"}},
+				},
+			},
+		}, {
+			name: "marks flag sets bookmarks in daemon mode",
+			e: &Emacs{
+				DaemonMode: true,
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("alpha.go"), "10", "20", "30", "--marks"},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf(`emacsclient -t -e '(progn (find-file "%s")(goto-line 10)(save-excursion (goto-char (point-min)) (forward-line 9) (bookmark-set "alpha.go:10"))(save-excursion (goto-char (point-min)) (forward-line 19) (bookmark-set "alpha.go:20"))(save-excursion (goto-char (point-min)) (forward-line 29) (bookmark-set "alpha.go:30")))'`, absPath(t, "alpha.go")),
+					},
+				},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg:         command.StringListValue(absPath(t, "alpha.go")),
+						lineArg:          command.IntListValue(10),
+						marksArg:         command.IntListValue(20, 30),
+						marksFlag.Name(): command.BoolValue(true),
+					},
+				},
+			},
+			want: &Emacs{
+				DaemonMode: true,
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go"), "10", "20", "30", "--marks"},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go")}, Args: []string{absPath(t, "alpha.go"), "10", "20", "30", "--marks"}},
+				},
+			},
+		}, {
+			name: "marks flag sets bookmarks after file line",
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("alpha.go:10"), "20", "-M"},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system +10 %s --eval '(save-excursion (goto-char (point-min)) (forward-line 9) (bookmark-set \"alpha.go:10\"))' --eval '(save-excursion (goto-char (point-min)) (forward-line 19) (bookmark-set \"alpha.go:20\"))'", absPath(t, "alpha.go")),
+					},
+				},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg:         command.StringListValue(absPath(t, "alpha.go")),
+						lineArg:          command.IntListValue(10),
+						marksArg:         command.IntListValue(20),
+						marksFlag.Name(): command.BoolValue(true),
+					},
+				},
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go:10"), "20", "-M"},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go")}, Args: []string{absPath(t, "alpha.go:10"), "20", "-M"}},
+				},
+			},
+		}, {
+			name: "marks flag fails with multiple files",
+			etc: &command.ExecuteTestCase{
+				Args:       []string{path("alpha.go"), path("alpha.txt"), "--marks"},
+				WantStderr: []string{`"marks" flag can only be used with a single file`},
+				WantErr:    fmt.Errorf(`"marks" flag can only be used with a single file`),
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg:         command.StringListValue(absPath(t, "alpha.go"), absPath(t, "alpha.txt")),
+						marksFlag.Name(): command.BoolValue(true),
+					},
+				},
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go"), absPath(t, "alpha.txt"), "--marks"},
+				},
+			},
+		}, {
+			name: "evaluates multiple eval flags",
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("alpha.go"), "--eval", "(flymake-mode)", "-E", "(whitespace-mode)"},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system %s --eval '(flymake-mode)' --eval '(whitespace-mode)'", absPath(t, "alpha.go")),
+					},
+				},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue(absPath(t, "alpha.go")),
+						evalArg:  command.StringListValue("(flymake-mode)", "(whitespace-mode)"),
+					},
+				},
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go"), "--eval", "(flymake-mode)", "-E", "(whitespace-mode)"},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go")}, Args: []string{absPath(t, "alpha.go"), "--eval", "(flymake-mode)", "-E", "(whitespace-mode)"}},
+				},
+			},
+		}, {
+			name: "cds into directory",
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("dirA")},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{fmt.Sprintf("cd %s", absPath(t, "dirA"))},
+				},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue(absPath(t, "dirA")),
+					},
+				},
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "dirA")},
+				},
+			},
+		}, {
+			name: "expands home directory in file arguments",
+			etc: &command.ExecuteTestCase{
+				Args: []string{"~/alpha.go"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue(absPath(t, "alpha.go")),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system %s", absPath(t, "alpha.go")),
+					},
+				},
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go")},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go")}, Args: []string{absPath(t, "alpha.go")}},
+				},
+			},
+		}, {
+			name: "expands environment variables in file arguments",
+			env: map[string]string{
+				"CHEM": absPath(t, "compounds"),
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"$CHEM/sodiumChloride", "${CHEM}/../alpha.go"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue(absPath(t, "compounds", "sodiumChloride"), absPath(t, "alpha.go")),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system %s %s %s", absPath(t, "alpha.go"), absPath(t, "compounds", "sodiumChloride"), splitEval(absPath(t, "alpha.go"))),
+					},
+				},
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "compounds", "sodiumChloride"), absPath(t, "alpha.go")},
+				},
+				History: []*historyEntry{
+					{
+						Files: []string{absPath(t, "compounds", "sodiumChloride"), absPath(t, "alpha.go")},
+						Args:  []string{absPath(t, "compounds", "sodiumChloride"), absPath(t, "alpha.go")},
+					},
+				},
+			},
+		}, {
+			name: "doesn't expand remote files",
+			env: map[string]string{
+				"HOSTS": "/etc/passwd",
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"/ssh:host:$HOSTS"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue("/ssh:host:$HOSTS"),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						"emacs --no-window-system /ssh:host:$HOSTS",
+					},
+				},
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {"/ssh:host:$HOSTS"},
+				},
+				History: []*historyEntry{
+					{Files: []string{"/ssh:host:$HOSTS"}, Args: []string{"/ssh:host:$HOSTS"}},
+				},
+			},
+		}, {
+			name: "fails if file does not exist and new flag not provided",
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("newFile.txt")},
+				WantStderr: []string{
+					fmt.Sprintf(`file %q does not exist; include "new" flag to create it`, absPath(t, "newFile.txt")),
+				},
+				WantErr: fmt.Errorf(`file %q does not exist; include "new" flag to create it`, absPath(t, "newFile.txt")),
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue(absPath(t, "newFile.txt")),
+					},
+				},
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "newFile.txt")},
+				},
+			},
+		}, {
+			name: "creates new file if short new flag is provided",
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("newFile.txt"), "-n"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg:   command.StringListValue(absPath(t, "newFile.txt")),
+						newFileArg: command.BoolValue(true),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system %s", absPath(t, "newFile.txt")),
+					},
+				},
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {
+						absPath(t, "newFile.txt"),
+						"-n",
+					},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "newFile.txt")}, Args: []string{absPath(t, "newFile.txt"), "-n"}},
+				},
+			},
+		}, {
+			name: "creates new file if new flag is provided",
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("newFile.txt"), "--new"},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system %s", absPath(t, "newFile.txt")),
+					},
+				},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg:   command.StringListValue(absPath(t, "newFile.txt")),
+						newFileArg: command.BoolValue(true),
+					},
+				},
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {
+						absPath(t, "newFile.txt"),
+						"--new",
+					},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "newFile.txt")}, Args: []string{absPath(t, "newFile.txt"), "--new"}},
+				},
+			},
+		}, {
+			name: "touch flag creates missing file",
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("newFile.txt"), "--touch"},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system %s", absPath(t, "newFile.txt")),
+					},
+				},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg:         command.StringListValue(absPath(t, "newFile.txt")),
+						touchFlag.Name(): command.BoolValue(true),
+					},
+				},
+			},
+			wantCreates: []string{absPath(t, "newFile.txt")},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "newFile.txt"), "--touch"},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "newFile.txt")}, Args: []string{absPath(t, "newFile.txt"), "--touch"}},
+				},
+			},
+		}, {
+			name: "touch flag creates missing file and directories with new flag",
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("newDir", "sub", "newFile.txt"), "-n", "-T"},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system %s", absPath(t, "newDir", "sub", "newFile.txt")),
+					},
+				},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg:         command.StringListValue(absPath(t, "newDir", "sub", "newFile.txt")),
+						newFileArg:       command.BoolValue(true),
+						touchFlag.Name(): command.BoolValue(true),
+					},
+				},
+			},
+			wantMkdirs:  []string{absPath(t, "newDir", "sub")},
+			wantCreates: []string{absPath(t, "newDir", "sub", "newFile.txt")},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "newDir", "sub", "newFile.txt"), "-n", "-T"},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "newDir", "sub", "newFile.txt")}, Args: []string{absPath(t, "newDir", "sub", "newFile.txt"), "-n", "-T"}},
+				},
+			},
+		}, {
+			name: "touch flag doesn't create existing file",
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("alpha.go"), "--touch"},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system %s", absPath(t, "alpha.go")),
+					},
+				},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg:         command.StringListValue(absPath(t, "alpha.go")),
+						touchFlag.Name(): command.BoolValue(true),
+					},
+				},
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go"), "--touch"},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go")}, Args: []string{absPath(t, "alpha.go"), "--touch"}},
+				},
+			},
+		}, {
+			name: "passes args after separator to emacs",
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("alpha.go"), "12", "--", "--fg-daemon", "-n", "3"},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system +12 %s --fg-daemon -n 3", absPath(t, "alpha.go")),
+					},
+				},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg:       command.StringListValue(absPath(t, "alpha.go")),
+						lineArg:        command.IntListValue(12),
+						passthroughArg: command.StringListValue("--fg-daemon", "-n", "3"),
+					},
+				},
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go"), "12", "--", "--fg-daemon", "-n", "3"},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go")}, Args: []string{absPath(t, "alpha.go"), "12", "--", "--fg-daemon", "-n", "3"}},
+				},
+			},
+		}, {
+			name: "passes alias flags after separator to emacs",
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("alpha.go"), "--", "-q", "-u", "-G", "x", "-k"},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system %s -q -u -G x -k", absPath(t, "alpha.go")),
+					},
+				},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg:       command.StringListValue(absPath(t, "alpha.go")),
+						passthroughArg: command.StringListValue("-q", "-u", "-G", "x", "-k"),
+					},
+				},
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go"), "--", "-q", "-u", "-G", "x", "-k"},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go")}, Args: []string{absPath(t, "alpha.go"), "--", "-q", "-u", "-G", "x", "-k"}},
+				},
+			},
+		}, {
+			name: "ignores passthrough args in daemon mode",
+			e: &Emacs{
+				DaemonMode: true,
+			},
+			etc: &command.ExecuteTestCase{
+				Args:       []string{path("alpha.go"), "--", "--fg-daemon"},
+				WantStderr: []string{`ignoring arguments after "--" since they are only passed to emacs in basic mode`},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf(`emacsclient -t -e '(progn (find-file "%s"))'`, absPath(t, "alpha.go")),
+					},
+				},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg:       command.StringListValue(absPath(t, "alpha.go")),
+						passthroughArg: command.StringListValue("--fg-daemon"),
+					},
+				},
+			},
+			want: &Emacs{
+				DaemonMode: true,
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go"), "--", "--fg-daemon"},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go")}, Args: []string{absPath(t, "alpha.go"), "--", "--fg-daemon"}},
+				},
+			},
+		}, {
+			name: "dir flag opens file directories in dired in daemon mode",
+			e: &Emacs{
+				DaemonMode: true,
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("catan", "oreAndWheat"), path("compounds", "sodiumChloride"), "--dir"},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf(`emacsclient -t -e '(progn (dired "%s")(dired-other-window "%s")(other-window 1))'`, absPath(t, "catan"), absPath(t, "compounds")),
+					},
+				},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg:         command.StringListValue(absPath(t, "catan", "oreAndWheat"), absPath(t, "compounds", "sodiumChloride")),
+						diredFlag.Name(): command.BoolValue(true),
+					},
+				},
+			},
+			want: &Emacs{
+				DaemonMode: true,
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "catan", "oreAndWheat"), absPath(t, "compounds", "sodiumChloride"), "--dir"},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "catan"), absPath(t, "compounds")}, Args: []string{absPath(t, "catan", "oreAndWheat"), absPath(t, "compounds", "sodiumChloride"), "--dir"}},
+				},
+			},
+		}, {
+			name: "dir flag opens file directory once",
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("alpha.go"), "12", path("alpha.txt"), "-D"},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system %s", absPath(t)),
+					},
+				},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg:         command.StringListValue(absPath(t, "alpha.go"), absPath(t, "alpha.txt")),
+						lineArg:          command.IntListValue(12),
+						diredFlag.Name(): command.BoolValue(true),
+					},
+				},
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go"), "12", absPath(t, "alpha.txt"), "-D"},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t)}, Args: []string{absPath(t, "alpha.go"), "12", absPath(t, "alpha.txt"), "-D"}},
+				},
+			},
+		}, {
+			name: "dir flag opens directory in dired",
+			e: &Emacs{
+				DaemonMode: true,
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("dirA") + "/", "--dir"},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf(`emacsclient -t -e '(progn (dired "%s"))'`, absPath(t, "dirA")),
+					},
+				},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg:         command.StringListValue(absPath(t, "dirA")),
+						diredFlag.Name(): command.BoolValue(true),
+					},
+				},
+			},
+			want: &Emacs{
+				DaemonMode: true,
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "dirA"), "--dir"},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "dirA")}, Args: []string{absPath(t, "dirA"), "--dir"}},
+				},
+			},
+		}, {
+			name: "new and readonly flags conflict",
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("newFile.txt"), "--new", "--readonly"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg:            command.StringListValue(absPath(t, "newFile.txt")),
+						newFileArg:          command.BoolValue(true),
+						readOnlyFlag.Name(): command.BoolValue(true),
+					},
+				},
+				WantStderr: []string{`"new" and "readonly" flags can't be used together`},
+				WantErr:    fmt.Errorf(`"new" and "readonly" flags can't be used together`),
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "newFile.txt"), "--new", "--readonly"},
+				},
+			},
+		}, {
+			name: "touch and dry-run flags conflict",
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("newFile.txt"), "-T", "-y"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg:          command.StringListValue(absPath(t, "newFile.txt")),
+						touchFlag.Name():  command.BoolValue(true),
+						dryRunFlag.Name(): command.BoolValue(true),
+					},
+				},
+				WantStderr: []string{`"touch" and "dry-run" flags can't be used together`},
+				WantErr:    fmt.Errorf(`"touch" and "dry-run" flags can't be used together`),
+			},
+		}, {
+			name: "dir and marks flags conflict",
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("alpha.go"), "1", "2", "--dir", "--marks"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg:         command.StringListValue(absPath(t, "alpha.go")),
+						lineArg:          command.IntListValue(1),
+						marksArg:         command.IntListValue(2),
+						diredFlag.Name(): command.BoolValue(true),
+						marksFlag.Name(): command.BoolValue(true),
+					},
+				},
+				WantStderr: []string{`"dir" and "marks" flags can't be used together`},
+				WantErr:    fmt.Errorf(`"dir" and "marks" flags can't be used together`),
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go"), "1", "2", "--dir", "--marks"},
+				},
+			},
+		}, {
+			name: "force-open flag opens missing file without creating directories",
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("newDir", "sub", "newFile.txt"), "--force-open"},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system %s", absPath(t, "newDir", "sub", "newFile.txt")),
+					},
+				},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg:             command.StringListValue(absPath(t, "newDir", "sub", "newFile.txt")),
+						forceOpenFlag.Name(): command.BoolValue(true),
+					},
+				},
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "newDir", "sub", "newFile.txt"), "--force-open"},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "newDir", "sub", "newFile.txt")}, Args: []string{absPath(t, "newDir", "sub", "newFile.txt"), "--force-open"}},
+				},
+			},
+		}, {
+			name: "short force-open flag works with sudo",
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("newFile.txt"), "-O", "-S"},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system /sudo::%s", absPath(t, "newFile.txt")),
+					},
+				},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg:             command.StringListValue(absPath(t, "newFile.txt")),
+						forceOpenFlag.Name(): command.BoolValue(true),
+						sudoFlag.Name():      command.BoolValue(true),
+					},
+				},
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "newFile.txt"), "-O", "-S"},
+				},
+				History: []*historyEntry{
+					{Files: []string{"/sudo::" + absPath(t, "newFile.txt")}, Args: []string{absPath(t, "newFile.txt"), "-O", "-S"}},
+				},
+			},
+		}, {
+			name: "creates parent directories of new file",
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("newDir", "sub", "newFile.txt"), "-n"},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system %s", absPath(t, "newDir", "sub", "newFile.txt")),
+					},
+				},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg:   command.StringListValue(absPath(t, "newDir", "sub", "newFile.txt")),
+						newFileArg: command.BoolValue(true),
+					},
+				},
+			},
+			wantMkdirs: []string{absPath(t, "newDir", "sub")},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "newDir", "sub", "newFile.txt"), "-n"},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "newDir", "sub", "newFile.txt")}, Args: []string{absPath(t, "newDir", "sub", "newFile.txt"), "-n"}},
+				},
+			},
+		}, {
+			name: "doesn't create existing parent directory of new file",
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("nested", "sub", "newFile.txt"), "-n"},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system %s", absPath(t, "nested", "sub", "newFile.txt")),
+					},
+				},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg:   command.StringListValue(absPath(t, "nested", "sub", "newFile.txt")),
+						newFileArg: command.BoolValue(true),
+					},
+				},
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "nested", "sub", "newFile.txt"), "-n"},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "nested", "sub", "newFile.txt")}, Args: []string{absPath(t, "nested", "sub", "newFile.txt"), "-n"}},
+				},
+			},
+		}, {
+			name: "handles all aliases",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"salt": {path("compounds", "sodiumChloride")},
+						"city": {path("catan", "oreAndWheat")},
+					},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"salt", "city"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue(absPath(t, "compounds", "sodiumChloride"), absPath(t, "catan", "oreAndWheat")),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system %s %s %s", absPath(t, "catan", "oreAndWheat"), absPath(t, "compounds", "sodiumChloride"), splitEval(absPath(t, "catan", "oreAndWheat"))),
+					},
+				},
+			},
+			want: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"salt": {path("compounds", "sodiumChloride")},
+						"city": {path("catan", "oreAndWheat")},
+					},
+				},
+				AliasUses: map[string]map[string]int{fileAliaserName: {"city": 1, "salt": 1}},
+				Caches: map[string][]string{cacheName: {
+					absPath(t, "compounds", "sodiumChloride"),
+					absPath(t, "catan", "oreAndWheat"),
+				}},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "compounds", "sodiumChloride"), absPath(t, "catan", "oreAndWheat")}, Args: []string{absPath(t, "compounds", "sodiumChloride"), absPath(t, "catan", "oreAndWheat")}},
+				},
+			},
+		}, {
+			name: "opens duplicate alias and path once",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"salt": {path("compounds", "sodiumChloride")},
+					},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"salt", path("compounds", "sodiumChloride")},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue(absPath(t, "compounds", "sodiumChloride"), absPath(t, "compounds", "sodiumChloride")),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system %s", absPath(t, "compounds", "sodiumChloride")),
+					},
+				},
+			},
+			want: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"salt": {path("compounds", "sodiumChloride")},
+					},
+				},
+				AliasUses: map[string]map[string]int{fileAliaserName: {"salt": 1}},
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "compounds", "sodiumChloride"), absPath(t, "compounds", "sodiumChloride")},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "compounds", "sodiumChloride")}, Args: []string{absPath(t, "compounds", "sodiumChloride"), absPath(t, "compounds", "sodiumChloride")}},
+				},
+			},
+		}, {
+			name: "opens missing file from alias added with new flag",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"notes": {absPath(t, "scratch.txt")},
+					},
+				},
+				NewFileAliases: map[string]map[string]bool{
+					fileAliaserName: {"notes": true},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"notes"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg:   command.StringListValue(absPath(t, "scratch.txt")),
+						newFileArg: command.BoolValue(true),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system %s", absPath(t, "scratch.txt")),
+					},
+				},
+			},
+			want: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"notes": {absPath(t, "scratch.txt")},
+					},
+				},
+				NewFileAliases: map[string]map[string]bool{
+					fileAliaserName: {"notes": true},
+				},
+				AliasUses: map[string]map[string]int{fileAliaserName: {"notes": 1}},
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "scratch.txt")},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "scratch.txt")}, Args: []string{absPath(t, "scratch.txt")}},
+				},
+			},
+		}, {
+			name: "opens missing file from later alias added with new flag",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"scratch": {absPath(t, "scratch.txt")},
+					},
+				},
+				NewFileAliases: map[string]map[string]bool{
+					fileAliaserName: {"scratch": true},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("alpha.txt"), "scratch"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg:   command.StringListValue(absPath(t, "alpha.txt"), absPath(t, "scratch.txt")),
+						newFileArg: command.BoolValue(true),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system %s %s %s", absPath(t, "scratch.txt"), absPath(t, "alpha.txt"), splitEval(absPath(t, "scratch.txt"))),
+					},
+				},
+			},
+			want: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"scratch": {absPath(t, "scratch.txt")},
+					},
+				},
+				NewFileAliases: map[string]map[string]bool{
+					fileAliaserName: {"scratch": true},
+				},
+				AliasUses: map[string]map[string]int{fileAliaserName: {"scratch": 1}},
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.txt"), absPath(t, "scratch.txt")},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.txt"), absPath(t, "scratch.txt")}, Args: []string{absPath(t, "alpha.txt"), absPath(t, "scratch.txt")}},
+				},
+			},
+		}, {
+			name: "dry run doesn't count alias use",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"salt": {path("compounds", "sodiumChloride")},
+					},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"salt", "--dry-run"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg:          command.StringListValue(absPath(t, "compounds", "sodiumChloride")),
+						dryRunFlag.Name(): command.BoolValue(true),
+					},
+				},
+				WantStdout: []string{
+					fmt.Sprintf("emacs --no-window-system %s", absPath(t, "compounds", "sodiumChloride")),
+				},
+			},
+		}, {
+			name: "no-history flag doesn't count alias use",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"salt": {path("compounds", "sodiumChloride")},
+					},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"salt", "-H"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg:             command.StringListValue(absPath(t, "compounds", "sodiumChloride")),
+						noHistoryFlag.Name(): command.BoolValue(true),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system %s", absPath(t, "compounds", "sodiumChloride")),
+					},
+				},
+			},
+		}, {
+			name: "resolves aliases case-insensitively",
+			e: &Emacs{
+				CaseInsensitiveAliases: true,
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"salt": {path("compounds", "sodiumChloride")},
+					},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"SaLT", "12"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue(absPath(t, "compounds", "sodiumChloride")),
+						lineArg:  command.IntListValue(12),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system +12 %s", absPath(t, "compounds", "sodiumChloride")),
+					},
+				},
+			},
+			want: &Emacs{
+				CaseInsensitiveAliases: true,
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"salt": {path("compounds", "sodiumChloride")},
+					},
+				},
+				AliasUses: map[string]map[string]int{fileAliaserName: {"salt": 1}},
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "compounds", "sodiumChloride"), "12"},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "compounds", "sodiumChloride")}, Args: []string{absPath(t, "compounds", "sodiumChloride"), "12"}},
+				},
+			},
+		}, {
+			name: "resolves later aliases case-insensitively",
+			e: &Emacs{
+				CaseInsensitiveAliases: true,
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"salt": {path("compounds", "sodiumChloride")},
+					},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("alpha.txt"), "SALT"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue(absPath(t, "alpha.txt"), absPath(t, "compounds", "sodiumChloride")),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system %s %s %s", absPath(t, "compounds", "sodiumChloride"), absPath(t, "alpha.txt"), splitEval(absPath(t, "compounds", "sodiumChloride"))),
+					},
+				},
+			},
+			want: &Emacs{
+				CaseInsensitiveAliases: true,
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"salt": {path("compounds", "sodiumChloride")},
+					},
+				},
+				AliasUses: map[string]map[string]int{fileAliaserName: {"salt": 1}},
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.txt"), absPath(t, "compounds", "sodiumChloride")},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.txt"), absPath(t, "compounds", "sodiumChloride")}, Args: []string{absPath(t, "alpha.txt"), absPath(t, "compounds", "sodiumChloride")}},
+				},
+			},
+		}, {
+			name: "aliases are case-sensitive by default",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"salt": {path("compounds", "sodiumChloride")},
+					},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"SALT"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue(absPath(t, "..", "SALT")),
+					},
+				},
+				WantStderr: []string{fmt.Sprintf(`file %q does not exist; include "new" flag to create it`, absPath(t, "..", "SALT"))},
+				WantErr:    fmt.Errorf(`file %q does not exist; include "new" flag to create it`, absPath(t, "..", "SALT")),
+			},
+			want: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"salt": {path("compounds", "sodiumChloride")},
+					},
+				},
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "..", "SALT")},
+				},
+			},
+		}, {
+			name: "opens shell command alias output in daemon mode",
+			e: &Emacs{
+				DaemonMode: true,
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"logs": {"!journalctl -u myservice"},
+					},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"logs"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue("!journalctl -u myservice"),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						`emacsclient -t -e '(progn (shell-command "journalctl -u myservice" "*journalctl -u myservice*")(switch-to-buffer "*journalctl -u myservice*"))'`,
+					},
+				},
+			},
+			want: &Emacs{
+				DaemonMode: true,
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"logs": {"!journalctl -u myservice"},
+					},
+				},
+				AliasUses: map[string]map[string]int{fileAliaserName: {"logs": 1}},
+				Caches: map[string][]string{
+					cacheName: {"!journalctl -u myservice"},
+				},
+				History: []*historyEntry{
+					{Files: []string{"!journalctl -u myservice"}, Args: []string{"!journalctl -u myservice"}},
+				},
+			},
+		}, {
+			name: "opens shell command alias output with file in daemon mode",
+			e: &Emacs{
+				DaemonMode: true,
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"logs": {"!grep -r 'TODO' ."},
+					},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("alpha.go"), "logs"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue(absPath(t, "alpha.go"), "!grep -r 'TODO' ."),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf(`emacsclient -t -e '(progn (find-file "%s")(shell-command "grep -r '\''TODO'\'' ." "*grep -r '\''TODO'\'' .*")(switch-to-buffer-other-window "*grep -r '\''TODO'\'' .*")(other-window 1))'`, absPath(t, "alpha.go")),
+					},
+				},
+			},
+			want: &Emacs{
+				DaemonMode: true,
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"logs": {"!grep -r 'TODO' ."},
+					},
+				},
+				AliasUses: map[string]map[string]int{fileAliaserName: {"logs": 1}},
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go"), "!grep -r 'TODO' ."},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go"), "!grep -r 'TODO' ."}, Args: []string{absPath(t, "alpha.go"), "!grep -r 'TODO' ."}},
+				},
+			},
+		}, {
+			name: "opens shell command alias output in basic mode",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"logs": {"!journalctl -u myservice"},
+					},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("alpha.go"), "logs"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue(absPath(t, "alpha.go"), "!journalctl -u myservice"),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf(`emacs --no-window-system --eval '(progn (shell-command "journalctl -u myservice" "*journalctl -u myservice*")(switch-to-buffer "*journalctl -u myservice*"))' %s --eval '(progn (split-window-right) (other-window 1) (switch-to-buffer "*journalctl -u myservice*") (other-window 1))'`, absPath(t, "alpha.go")),
+					},
+				},
+			},
+			want: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"logs": {"!journalctl -u myservice"},
+					},
+				},
+				AliasUses: map[string]map[string]int{fileAliaserName: {"logs": 1}},
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go"), "!journalctl -u myservice"},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go"), "!journalctl -u myservice"}, Args: []string{absPath(t, "alpha.go"), "!journalctl -u myservice"}},
+				},
+			},
+		}, {
+			name: "opens bookmark in daemon mode",
+			e: &Emacs{
+				DaemonMode: true,
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"@@todo"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue("@@todo"),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						`emacsclient -t -e '(progn (bookmark-jump "todo"))'`,
+					},
+				},
+			},
+			want: &Emacs{
+				DaemonMode: true,
+				Caches: map[string][]string{
+					cacheName: {"@@todo"},
+				},
+				History: []*historyEntry{
+					{Files: []string{"@@todo"}, Args: []string{"@@todo"}},
+				},
+			},
+		}, {
+			name: "opens bookmark after file in daemon mode",
+			e: &Emacs{
+				DaemonMode: true,
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("alpha.go"), "@@todo"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue(absPath(t, "alpha.go"), "@@todo"),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf(`emacsclient -t -e '(progn (find-file "%s")(bookmark-jump-other-window "todo")(other-window 1))'`, absPath(t, "alpha.go")),
+					},
+				},
+			},
+			want: &Emacs{
+				DaemonMode: true,
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go"), "@@todo"},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go"), "@@todo"}, Args: []string{absPath(t, "alpha.go"), "@@todo"}},
+				},
+			},
+		}, {
+			name: "opens bookmark in basic mode",
+			env: map[string]string{
+				"EMACS_BOOKMARKS": absPath(t, "alpha.txt"),
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"@@todo", path("alpha.go")},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue("@@todo", absPath(t, "alpha.go")),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf(`emacs --no-window-system %s --eval '(bookmark-jump "todo")' --eval '(progn (split-window-right) (other-window 1) (switch-to-buffer (get-file-buffer "%s")) (other-window 1))'`, absPath(t, "alpha.go"), absPath(t, "alpha.go")),
+					},
+				},
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {"@@todo", absPath(t, "alpha.go")},
+				},
+				History: []*historyEntry{
+					{Files: []string{"@@todo", absPath(t, "alpha.go")}, Args: []string{"@@todo", absPath(t, "alpha.go")}},
+				},
+			},
+		}, {
+			name: "bookmark errors in basic mode without a bookmark file",
+			env: map[string]string{
+				"EMACS_BOOKMARKS": absPath(t, "bookmarks"),
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"@@todo"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue("@@todo"),
+					},
+				},
+				WantErr:    fmt.Errorf(`can't jump to bookmark "todo" in basic mode since the bookmark file %q doesn't exist`, absPath(t, "bookmarks")),
+				WantStderr: []string{fmt.Sprintf(`can't jump to bookmark "todo" in basic mode since the bookmark file %q doesn't exist`, absPath(t, "bookmarks"))},
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {"@@todo"},
+				},
+			},
+		}, {
+			name: "handles line numbers",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"salt": {path("compounds", "sodiumChloride")},
+						"city": {path("catan", "oreAndWheat")},
+					},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("alpha.txt"), "salt", "32"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue(
+							absPath(t, "alpha.txt"),
+							absPath(t, "compounds", "sodiumChloride"),
+						),
+						lineArg: command.IntListValue(0, 32),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system +32 %s %s %s", absPath(t, "compounds", "sodiumChloride"), absPath(t, "alpha.txt"), splitEval(absPath(t, "compounds", "sodiumChloride"))),
+					},
+				},
+			},
+			want: &Emacs{
+				Aliases: map[string]map[string][]string{fileAliaserName: {
+					"salt": {path("compounds", "sodiumChloride")},
+					"city": {path("catan", "oreAndWheat")},
+				},
+				},
+				AliasUses: map[string]map[string]int{fileAliaserName: {"salt": 1}},
+				Caches: map[string][]string{
+					cacheName: {
+						absPath(t, "alpha.txt"),
+						absPath(t, "compounds", "sodiumChloride"),
+						"32",
+					},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.txt"), absPath(t, "compounds", "sodiumChloride")}, Args: []string{absPath(t, "alpha.txt"), absPath(t, "compounds", "sodiumChloride"), "32"}},
+				},
+			},
+		}, {
+			name: "opens files at end",
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("alpha.txt"), "$", path("alpha.go"), "-0"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue(absPath(t, "alpha.txt"), absPath(t, "alpha.go")),
+						endArg:   command.IntListValue(1, 1),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf(`emacs --no-window-system --eval '(progn (find-file "%s") (goto-char (point-max)))' --eval '(progn (find-file "%s") (goto-char (point-max)))' %s`, absPath(t, "alpha.go"), absPath(t, "alpha.txt"), splitEval(absPath(t, "alpha.go"))),
+					},
+				},
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.txt"), "$", absPath(t, "alpha.go"), "-0"},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.txt"), absPath(t, "alpha.go")}, Args: []string{absPath(t, "alpha.txt"), "$", absPath(t, "alpha.go"), "-0"}},
+				},
+			},
+		}, {
+			name: "opens files relative to their end",
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("alpha.txt"), "-5"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue(absPath(t, "alpha.txt")),
+						lineArg:  command.IntListValue(-5),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf(`emacs --no-window-system --eval '(progn (find-file "%s") (goto-char (point-max)) (forward-line -5))'`, absPath(t, "alpha.txt")),
+					},
+				},
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.txt"), "-5"},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.txt")}, Args: []string{absPath(t, "alpha.txt"), "-5"}},
+				},
+			},
+		}, {
+			name: "opens files at a percentage",
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("alpha.txt"), "%50"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg:   command.StringListValue(absPath(t, "alpha.txt")),
+						percentArg: command.StringListValue("50"),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf(`emacs --no-window-system --eval '(progn (find-file "%s") (goto-char (/ (* 50 (point-max)) 100)))'`, absPath(t, "alpha.txt")),
+					},
+				},
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.txt"), "%50"},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.txt")}, Args: []string{absPath(t, "alpha.txt"), "%50"}},
+				},
+			},
+		}, {
+			name: "percentage must be at most 100",
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("alpha.txt"), "%150"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg:   command.StringListValue(absPath(t, "alpha.txt")),
+						percentArg: command.StringListValue("150"),
+					},
+				},
+				WantStderr: []string{"validation failed: percentage must be between 0 and 100"},
+				WantErr:    fmt.Errorf("validation failed: percentage must be between 0 and 100"),
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.txt"), "%150"},
+				},
+			},
+		}, {
+			name: "opens second file at end",
+			e: &Emacs{
+				DaemonMode: true,
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("alpha.txt"), "12", path("alpha.go"), "$"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue(absPath(t, "alpha.txt"), absPath(t, "alpha.go")),
+						lineArg:  command.IntListValue(12),
+						endArg:   command.IntListValue(0, 1),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf(`emacsclient -t -e '(progn (find-file "%s")(goto-line 12)(find-file-other-window "%s")(goto-char (point-max))(other-window 1))'`, absPath(t, "alpha.txt"), absPath(t, "alpha.go")),
+					},
+				},
+			},
+			want: &Emacs{
+				DaemonMode: true,
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.txt"), "12", absPath(t, "alpha.go"), "$"},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.txt"), absPath(t, "alpha.go")}, Args: []string{absPath(t, "alpha.txt"), "12", absPath(t, "alpha.go"), "$"}},
+				},
+			},
+		}, {
+			name: "handles line and column numbers",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"salt": {path("compounds", "sodiumChloride")},
+					},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("alpha.txt"), "7", "salt", "32:8"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue(
+							absPath(t, "alpha.txt"),
+							absPath(t, "compounds", "sodiumChloride"),
+						),
+						lineArg:   command.IntListValue(7, 32),
+						columnArg: command.IntListValue(0, 8),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system +32:8 %s +7 %s %s", absPath(t, "compounds", "sodiumChloride"), absPath(t, "alpha.txt"), splitEval(absPath(t, "compounds", "sodiumChloride"))),
+					},
+				},
+			},
+			want: &Emacs{
+				Aliases: map[string]map[string][]string{fileAliaserName: {
+					"salt": {path("compounds", "sodiumChloride")},
+				},
+				},
+				AliasUses: map[string]map[string]int{fileAliaserName: {"salt": 1}},
+				Caches: map[string][]string{
+					cacheName: {
+						absPath(t, "alpha.txt"),
+						"7",
+						absPath(t, "compounds", "sodiumChloride"),
+						"32:8",
+					},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.txt"), absPath(t, "compounds", "sodiumChloride")}, Args: []string{absPath(t, "alpha.txt"), "7", absPath(t, "compounds", "sodiumChloride"), "32:8"}},
+				},
+			},
+		}, {
+			name: "handles file:line arguments",
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("alpha.go") + ":120", path("alpha.txt")},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue(absPath(t, "alpha.go"), absPath(t, "alpha.txt")),
+						lineArg:  command.IntListValue(120),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system %s +120 %s %s", absPath(t, "alpha.txt"), absPath(t, "alpha.go"), splitEval(absPath(t, "alpha.txt"))),
+					},
+				},
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go") + ":120", absPath(t, "alpha.txt")},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go"), absPath(t, "alpha.txt")}, Args: []string{absPath(t, "alpha.go") + ":120", absPath(t, "alpha.txt")}},
+				},
+			},
+		}, {
+			name: "handles file:line:col arguments",
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("alpha.go") + ":120:7", path("alpha.txt") + ":3"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg:  command.StringListValue(absPath(t, "alpha.go"), absPath(t, "alpha.txt")),
+						lineArg:   command.IntListValue(120, 3),
+						columnArg: command.IntListValue(7),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system +3 %s +120:7 %s %s", absPath(t, "alpha.txt"), absPath(t, "alpha.go"), splitEval(absPath(t, "alpha.txt"))),
+					},
+				},
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go") + ":120:7", absPath(t, "alpha.txt") + ":3"},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go"), absPath(t, "alpha.txt")}, Args: []string{absPath(t, "alpha.go") + ":120:7", absPath(t, "alpha.txt") + ":3"}},
+				},
+			},
+		}, {
+			name: "handles file:line arguments for remote files",
+			etc: &command.ExecuteTestCase{
+				Args: []string{"/ssh:host:/etc/hosts:3", path("alpha.go") + ":120"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue("/ssh:host:/etc/hosts", absPath(t, "alpha.go")),
+						lineArg:  command.IntListValue(3, 120),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system +120 %s +3 /ssh:host:/etc/hosts %s", absPath(t, "alpha.go"), splitEval(absPath(t, "alpha.go"))),
+					},
+				},
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {"/ssh:host:/etc/hosts:3", absPath(t, "alpha.go") + ":120"},
+				},
+				History: []*historyEntry{
+					{Files: []string{"/ssh:host:/etc/hosts", absPath(t, "alpha.go")}, Args: []string{"/ssh:host:/etc/hosts:3", absPath(t, "alpha.go") + ":120"}},
+				},
+			},
+		}, {
+			name: "handles multiple numbers with number filename",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"salt": {path("compounds", "sodiumChloride")},
+						"city": {path("catan", "oreAndWheat")},
+					},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"salt", "32", path("42")},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue(absPath(t, "compounds", "sodiumChloride"), absPath(t, "42")),
+						lineArg:  command.IntListValue(32),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system %s +32 %s %s", absPath(t, "42"), absPath(t, "compounds", "sodiumChloride"), splitEval(absPath(t, "42"))),
+					},
+				},
+			},
+			want: &Emacs{
+				Aliases: map[string]map[string][]string{fileAliaserName: {
+					"salt": {path("compounds", "sodiumChloride")},
+					"city": {path("catan", "oreAndWheat")},
+				},
+				},
+				AliasUses: map[string]map[string]int{fileAliaserName: {"salt": 1}},
+				Caches: map[string][]string{
+					cacheName: {
+						absPath(t, "compounds", "sodiumChloride"),
+						"32",
+						absPath(t, "42"),
+					},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "compounds", "sodiumChloride"), absPath(t, "42")}, Args: []string{absPath(t, "compounds", "sodiumChloride"), "32", absPath(t, "42")}},
+				},
+			},
+		}, {
+			name: "adds to previous executions",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"city": {path("catan", "oreAndWheat")},
+					},
+				},
+				Caches: map[string][]string{
+					cacheName: {
+						"firstFile",
+					},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("luckyNumberThree")},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue(absPath(t, "luckyNumberThree")),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system %s", absPath(t, "luckyNumberThree")),
+					},
+				},
+			},
+			want: &Emacs{
+				Aliases: map[string]map[string][]string{fileAliaserName: {
+					"city": {path("catan", "oreAndWheat")},
+				},
+				},
+				Caches: map[string][]string{
+					cacheName: {
+						absPath(t, "luckyNumberThree"),
+					},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "luckyNumberThree")}, Args: []string{absPath(t, "luckyNumberThree")}},
+				},
+			},
+		}, {
+			name: "reduces size of previous executions if at limit",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"city": {path("catan", "oreAndWheat")},
+					},
+				},
+				Caches: map[string][]string{
+					cacheName: {
+						"firstFile",
+					},
+				},
+				History: append([]*historyEntry{{Files: []string{"oldestFile"}}}, historyOf(historyLimit-1, "firstFile")...),
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("luckyNumberThree")},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue(absPath(t, "luckyNumberThree")),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system %s", absPath(t, "luckyNumberThree")),
+					},
+				},
+			},
+			want: &Emacs{
+				Aliases: map[string]map[string][]string{fileAliaserName: {
+					"city": {path("catan", "oreAndWheat")},
+				}},
+				Caches: map[string][]string{
+					cacheName: {
+						absPath(t, "luckyNumberThree"),
+					},
+				},
+				History: append(historyOf(historyLimit-1, "firstFile"), &historyEntry{Files: []string{absPath(t, "luckyNumberThree")}, Args: []string{absPath(t, "luckyNumberThree")}}),
+			},
+		}, {
+			name: "reduces size of previous executions to configured limit",
+			e: &Emacs{
+				HistoryLimit: 2,
+				History:      historyOf(2, "firstFile"),
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("alpha.go")},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue(absPath(t, "alpha.go")),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system %s", absPath(t, "alpha.go")),
+					},
+				},
+			},
+			want: &Emacs{
+				HistoryLimit: 2,
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go")},
+				},
+				History: append(historyOf(1, "firstFile"), &historyEntry{Files: []string{absPath(t, "alpha.go")}, Args: []string{absPath(t, "alpha.go")}}),
+			},
+		}, {
+			name: "sets history limit",
+			e: &Emacs{
+				History: historyOf(3, "firstFile"),
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"settings", "limit", "2"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						limitArg: command.IntValue(2),
+					},
+				},
+				WantStdout: []string{"History limit set to 2."},
+			},
+			want: &Emacs{
+				HistoryLimit: 2,
+				History:      historyOf(2, "firstFile"),
+			},
+		}, {
+			name: "history limit must be positive",
+			etc: &command.ExecuteTestCase{
+				Args: []string{"settings", "limit", "0"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						limitArg: command.IntValue(0),
+					},
+				},
+				WantStderr: []string{"validation failed: [IntPositive] value isn't positive"},
+				WantErr:    fmt.Errorf("validation failed: [IntPositive] value isn't positive"),
+			},
+		}, {
+			name: "sets max file size",
+			etc: &command.ExecuteTestCase{
+				Args: []string{"settings", "maxsize", "1000"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						maxSizeArg: command.IntValue(1000),
+					},
+				},
+				WantStdout: []string{"Max file size set to 1000 bytes."},
+			},
+			want: &Emacs{
+				MaxSize: 1000,
+			},
+		}, {
+			name: "unsets max file size",
+			e: &Emacs{
+				MaxSize: 1000,
+			},
+			etc: &command.ExecuteTestCase{
+				Args:       []string{"settings", "maxsize"},
+				WantStdout: []string{"Max file size unset."},
+			},
+			want: &Emacs{},
+		}, {
+			name: "warns when opening large file",
+			e: &Emacs{
+				MaxSize: 1000,
+			},
+			fileInfos: map[string]os.FileInfo{
+				absPath(t, "alpha.go"): fakeFileInfo{mode: 0644, size: 1001},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("alpha.go")},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue(absPath(t, "alpha.go")),
+					},
+				},
+				WantStderr: []string{fmt.Sprintf(`file %q is larger than 1000 bytes; include "large" flag to open it in fundamental mode`, absPath(t, "alpha.go"))},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system %s", absPath(t, "alpha.go")),
+					},
+				},
+			},
+			want: &Emacs{
+				MaxSize: 1000,
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go")},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go")}, Args: []string{absPath(t, "alpha.go")}},
+				},
+			},
+		}, {
+			name: "doesn't warn when file is at max size",
+			e: &Emacs{
+				MaxSize: 1000,
+			},
+			fileInfos: map[string]os.FileInfo{
+				absPath(t, "alpha.go"): fakeFileInfo{mode: 0644, size: 1000},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("alpha.go")},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue(absPath(t, "alpha.go")),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system %s", absPath(t, "alpha.go")),
+					},
+				},
+			},
+			want: &Emacs{
+				MaxSize: 1000,
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go")},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go")}, Args: []string{absPath(t, "alpha.go")}},
+				},
+			},
+		}, {
+			name: "large flag opens file in fundamental mode",
+			e: &Emacs{
+				MaxSize: 1000,
+			},
+			fileInfos: map[string]os.FileInfo{
+				absPath(t, "alpha.go"): fakeFileInfo{mode: 0644, size: 1001},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("alpha.go"), "--large"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg:         command.StringListValue(absPath(t, "alpha.go")),
+						largeFlag.Name(): command.BoolValue(true),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system %s --eval '(fundamental-mode)'", absPath(t, "alpha.go")),
+					},
+				},
+			},
+			want: &Emacs{
+				MaxSize: 1000,
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go"), "--large"},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go")}, Args: []string{absPath(t, "alpha.go"), "--large"}},
+				},
+			},
+		}, {
+			name: "if empty cache and no arguments, error",
+			etc: &command.ExecuteTestCase{
+				WantErr:    fmt.Errorf("not enough arguments"),
+				WantStderr: []string{"not enough arguments"},
+			},
+		}, {
+			name: "clears the cache",
+			e: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {
+						absPath(t, "alpha.go"),
+					},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args:       []string{"clear"},
+				WantStdout: []string{"Cache cleared."},
+			},
+			want: &Emacs{},
+		}, {
+			name: "shows the cached command",
+			e: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go"), "12", "-r"},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args:       []string{"show"},
+				WantStdout: []string{fmt.Sprintf("%s 12 -r", absPath(t, "alpha.go"))},
+			},
+		}, {
+			name: "show fails if there is no cached command",
+			etc: &command.ExecuteTestCase{
+				Args:       []string{"show"},
+				WantStderr: []string{"no cached command"},
+				WantErr:    fmt.Errorf("no cached command"),
+			},
+		}, {
+			name: "outputs bash completion script",
+			etc: &command.ExecuteTestCase{
+				Args: []string{"completion", "bash"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						shellArg: command.StringValue("bash"),
+					},
+				},
+				WantStdout: []string{strings.Join([]string{
+					"function _custom_autocomplete {",
+					"  tFile=$(mktemp)",
+					"  $GOPATH/bin/leep-frog-source autocomplete $COMP_CWORD.$COMP_POINT $COMP_LINE > $tFile",
+					"  local IFS=$'\\n'",
+					"  COMPREPLY=( $(cat $tFile) )",
+					"  rm $tFile",
+					"}",
+					"complete -F _custom_autocomplete -o nosort e",
+				}, "\n")},
+			},
+		}, {
+			name: "outputs zsh completion script",
+			etc: &command.ExecuteTestCase{
+				Args: []string{"completion", "zsh"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						shellArg: command.StringValue("zsh"),
+					},
+				},
+				WantStdout: []string{strings.Join([]string{
+					"autoload -U +X bashcompinit && bashcompinit",
+					"function _custom_autocomplete {",
+					"  tFile=$(mktemp)",
+					"  $GOPATH/bin/leep-frog-source autocomplete $COMP_CWORD.$COMP_POINT $COMP_LINE > $tFile",
+					"  local IFS=$'\\n'",
+					"  COMPREPLY=( $(cat $tFile) )",
+					"  rm $tFile",
+					"}",
+					"complete -F _custom_autocomplete -o nosort e",
+				}, "\n")},
+			},
+		}, {
+			name: "completion requires a supported shell",
+			etc: &command.ExecuteTestCase{
+				Args: []string{"completion", "fish"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						shellArg: command.StringValue("fish"),
+					},
+				},
+				WantStderr: []string{`validation failed: value must be "bash" or "zsh"`},
+				WantErr:    fmt.Errorf(`validation failed: value must be "bash" or "zsh"`),
+			},
+		}, {
+			name: "no arguments after clearing the cache is an error",
+			e: &Emacs{
+				Caches: map[string][]string{},
+			},
+			etc: &command.ExecuteTestCase{
+				WantErr:    fmt.Errorf("not enough arguments"),
+				WantStderr: []string{"not enough arguments"},
+			},
+		}, {
+			name: "if nil argument, run last command",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"city": {path("catan", "oreAndWheat")},
+					}},
+				Caches: map[string][]string{
+					cacheName: {
+						absPath(t, "alpha.go"),
+					},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system %s", absPath(t, "alpha.go")),
+					},
+				},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue(absPath(t, "alpha.go")),
+					},
+				},
+			},
+			want: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"city": {path("catan", "oreAndWheat")},
+					}},
+				Caches: map[string][]string{
+					cacheName: {
+						absPath(t, "alpha.go"),
+					},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go")}, Args: []string{absPath(t, "alpha.go")}},
+				},
+			},
+		}, {
+			name: "if empty arguments, run last command",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"city": {path("catan", "oreAndWheat")},
+					}},
+				Caches: map[string][]string{
+					cacheName: {
+						absPath(t, "alpha.go"),
+					},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system %s", absPath(t, "alpha.go")),
+					},
+				},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue(absPath(t, "alpha.go")),
+					},
+				},
+			},
+			want: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"city": {path("catan", "oreAndWheat")},
+					}},
+				Caches: map[string][]string{
+					cacheName: {
+						absPath(t, "alpha.go"),
+					},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go")}, Args: []string{absPath(t, "alpha.go")}},
+				},
+			},
+		}, // AddAlias tests
+		{
+			name: "fails if no alias",
+			etc: &command.ExecuteTestCase{
+				Args:       []string{"a"},
+				WantStderr: []string{"not enough arguments"},
+				WantErr:    fmt.Errorf("not enough arguments"),
+			},
+		},
+		{
+			name: "handles more than one arguments",
+			etc: &command.ExecuteTestCase{
+				WantStdout: []string{
+					fmt.Sprintf("Added alias duo: %s %s", absPath(t, "alpha.go"), absPath(t, "alpha.txt")),
+				},
+				Args: []string{"a", "duo", path("alpha.go"), path("alpha.txt")},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						"ALIAS":  command.StringValue("duo"),
+						emacsArg: command.StringListValue(absPath(t, "alpha.go"), absPath(t, "alpha.txt")),
+					},
+				},
+			},
+			want: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"duo": []string{absPath(t, "alpha.go"), absPath(t, "alpha.txt")},
+					},
+				},
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go"), absPath(t, "alpha.txt")},
+				},
+			},
+		}, {
+			name: "alias stores line numbers",
+			etc: &command.ExecuteTestCase{
+				WantStdout: []string{
+					fmt.Sprintf("Added alias bug: %s 42", absPath(t, "alpha.go")),
+				},
+				Args: []string{"a", "bug", path("alpha.go"), "42"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						"ALIAS":  command.StringValue("bug"),
+						emacsArg: command.StringListValue(absPath(t, "alpha.go")),
+						lineArg:  command.IntListValue(42),
+					},
+				},
+			},
+			want: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"bug": []string{absPath(t, "alpha.go"), "42"},
+					},
+				},
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go"), "42"},
+				},
+			},
+		}, {
+			name: "alias expands to files and line numbers",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"bug": []string{absPath(t, "alpha.go"), "42"},
+					},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("alpha.txt"), "bug"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue(absPath(t, "alpha.txt"), absPath(t, "alpha.go")),
+						lineArg:  command.IntListValue(0, 42),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system +42 %s %s %s", absPath(t, "alpha.go"), absPath(t, "alpha.txt"), splitEval(absPath(t, "alpha.go"))),
+					},
+				},
+			},
+			want: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"bug": []string{absPath(t, "alpha.go"), "42"},
+					},
+				},
+				AliasUses: map[string]map[string]int{fileAliaserName: {"bug": 1}},
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.txt"), absPath(t, "alpha.go"), "42"},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.txt"), absPath(t, "alpha.go")}, Args: []string{absPath(t, "alpha.txt"), absPath(t, "alpha.go"), "42"}},
+				},
+			},
+		}, {
+			name: "fails if alias already defined",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"salt": {"NaCl"},
+					}},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"a", "salt", "sodiumChloride"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						"ALIAS": command.StringValue("salt"),
+					},
+				},
+				WantStderr: []string{`Alias "salt" already exists`},
+				WantErr:    fmt.Errorf(`Alias "salt" already exists`),
+			},
+		}, {
+			name: "fails if file does not exist",
+			etc: &command.ExecuteTestCase{
+				Args: []string{"a", "nf", path("newFile.txt")},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						"ALIAS":  command.StringValue("nf"),
+						emacsArg: command.StringListValue(absPath(t, "newFile.txt")),
+					},
+				},
+				WantErr:    fmt.Errorf(`file %q does not exist; include "new" flag to create it`, absPath(t, "newFile.txt")),
+				WantStderr: []string{fmt.Sprintf(`file %q does not exist; include "new" flag to create it`, absPath(t, "newFile.txt"))},
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "newFile.txt")},
+				},
+			},
+		}, {
+			name: "adds alias for new file",
+			etc: &command.ExecuteTestCase{
+				Args: []string{"a", "nf", path("newFile.txt"), "--new"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						"ALIAS":    command.StringValue("nf"),
+						emacsArg:   command.StringListValue(absPath(t, "newFile.txt")),
+						newFileArg: command.BoolValue(true),
+					},
+				},
+				WantStdout: []string{
+					fmt.Sprintf("Added alias nf: %s", absPath(t, "newFile.txt")),
+				},
+			},
+			wantCreates: []string{absPath(t, "newFile.txt")},
+			want: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"nf": {absPath(t, "newFile.txt")},
+					},
+				},
+				NewFileAliases: map[string]map[string]bool{
+					fileAliaserName: {
+						"nf": true,
+					},
+				},
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "newFile.txt")},
+				},
+			},
+		}, {
+			name: "re-adding alias without new flag clears stale new file flag and use count",
+			e: &Emacs{
+				NewFileAliases: map[string]map[string]bool{
+					fileAliaserName: {
+						"nf": true,
+					},
+				},
+				AliasUses: map[string]map[string]int{
+					fileAliaserName: {
+						"nf": 7,
+					},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"a", "nf", path("alpha.txt")},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						"ALIAS":  command.StringValue("nf"),
+						emacsArg: command.StringListValue(absPath(t, "alpha.txt")),
+					},
+				},
+				WantStdout: []string{
+					fmt.Sprintf("Added alias nf: %s", absPath(t, "alpha.txt")),
+				},
+			},
+			want: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"nf": {absPath(t, "alpha.txt")},
+					},
+				},
+				NewFileAliases: map[string]map[string]bool{fileAliaserName: {}},
+				AliasUses:      map[string]map[string]int{fileAliaserName: {}},
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.txt")},
+				},
+			},
+		}, {
+			name: "adds alias for new file in new directory",
+			etc: &command.ExecuteTestCase{
+				Args: []string{"a", "nf", path("newDir", "newFile.txt"), "-n"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						"ALIAS":    command.StringValue("nf"),
+						emacsArg:   command.StringListValue(absPath(t, "newDir", "newFile.txt")),
+						newFileArg: command.BoolValue(true),
+					},
+				},
+				WantStdout: []string{
+					fmt.Sprintf("Added alias nf: %s", absPath(t, "newDir", "newFile.txt")),
+				},
+			},
+			wantMkdirs:  []string{absPath(t, "newDir")},
+			wantCreates: []string{absPath(t, "newDir", "newFile.txt")},
+			want: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"nf": {absPath(t, "newDir", "newFile.txt")},
+					},
+				},
+				NewFileAliases: map[string]map[string]bool{
+					fileAliaserName: {
+						"nf": true,
+					},
+				},
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "newDir", "newFile.txt")},
+				},
+			},
+		}, {
+			name: "adds shell command alias",
+			etc: &command.ExecuteTestCase{
+				Args: []string{"a", "logs", "!journalctl -u myservice"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						"ALIAS":  command.StringValue("logs"),
+						emacsArg: command.StringListValue("!journalctl -u myservice"),
+					},
+				},
+				WantStdout: []string{"Added alias logs: !journalctl -u myservice"},
+			},
+			want: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"logs": {"!journalctl -u myservice"},
+					},
+				},
+				Caches: map[string][]string{
+					cacheName: {"!journalctl -u myservice"},
+				},
+			},
+		}, {
+			name: "adds to nil aliases",
+			etc: &command.ExecuteTestCase{
+				WantStdout: []string{
+					fmt.Sprintf("Added alias uno: %s", absPath(t, "alpha.go")),
+				},
+				Args: []string{"a", "uno", path("alpha.go")},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						"ALIAS":  command.StringValue("uno"),
+						emacsArg: command.StringListValue(absPath(t, "alpha.go")),
+					},
+				},
+			},
+			want: &Emacs{
+				Aliases: map[string]map[string][]string{fileAliaserName: {
+					"uno": {absPath(t, "alpha.go")},
+				}},
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go")},
+				},
+			},
+		}, {
+			name: "adds to empty aliases",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				WantStdout: []string{
+					fmt.Sprintf("Added alias uno: %s", absPath(t, "alpha.txt")),
+				},
+				Args: []string{"a", "uno", path("alpha.txt")},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						"ALIAS":  command.StringValue("uno"),
+						emacsArg: command.StringListValue(absPath(t, "alpha.txt")),
+					},
+				},
+			},
+			want: &Emacs{
+				Aliases: map[string]map[string][]string{fileAliaserName: {
+					"uno": {absPath(t, "alpha.txt")},
+				}},
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.txt")},
+				},
+			},
+		}, {
+			name: "adds to aliases",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"other": {path("things")},
+						"ab":    {path("cd")},
+					},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				WantStdout: []string{
+					fmt.Sprintf("Added alias un: %s", absPath(t, "alpha.go")),
+				},
+				Args: []string{"a", "un", path("alpha.go")},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						"ALIAS":  command.StringValue("un"),
+						emacsArg: command.StringListValue(absPath(t, "alpha.go")),
+					},
+				},
+			},
+			want: &Emacs{
+				Aliases: map[string]map[string][]string{fileAliaserName: {
+					"other": {path("things")},
+					"ab":    {path("cd")},
+					"un":    {absPath(t, "alpha.go")},
+				}},
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go")},
+				},
+			},
+		}, {
+			name: "adds alias for directory",
+			etc: &command.ExecuteTestCase{
+				WantStdout: []string{
+					fmt.Sprintf("Added alias t: %s", absPath(t, "dirA")),
+				},
+				Args: []string{"a", "t", path("dirA")},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						"ALIAS":  command.StringValue("t"),
+						emacsArg: command.StringListValue(absPath(t, "dirA")),
+					},
+				},
+			},
+			want: &Emacs{
+				Aliases: map[string]map[string][]string{fileAliaserName: {
+					"t": {absPath(t, "dirA")},
+				}},
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "dirA")},
+				},
+			},
+		}, {
+			name: "quiet flag suppresses add summary",
+			etc: &command.ExecuteTestCase{
+				Args: []string{"a", "uno", path("alpha.go"), "--quiet"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						"ALIAS":          command.StringValue("uno"),
+						emacsArg:         command.StringListValue(absPath(t, "alpha.go")),
+						quietFlag.Name(): command.BoolValue(true),
+					},
+				},
+			},
+			want: &Emacs{
+				Aliases: map[string]map[string][]string{fileAliaserName: {
+					"uno": {absPath(t, "alpha.go")},
+				}},
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go")},
+				},
+			},
+		}, {
+			name: "warns if alias has same files as existing alias",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"duo":   {absPath(t, "alpha.txt"), absPath(t, "alpha.go")},
+						"alpha": {absPath(t, "alpha.go")},
+					},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"a", "pair", path("alpha.go"), path("alpha.txt")},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						"ALIAS":  command.StringValue("pair"),
+						emacsArg: command.StringListValue(absPath(t, "alpha.go"), absPath(t, "alpha.txt")),
+					},
+				},
+				WantStdout: []string{
+					fmt.Sprintf("Added alias pair: %s %s", absPath(t, "alpha.go"), absPath(t, "alpha.txt")),
+				},
+				WantStderr: []string{
+					`Warning: alias "pair" has the same files as existing alias "duo"`,
+				},
+			},
+			want: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"duo":   {absPath(t, "alpha.txt"), absPath(t, "alpha.go")},
+						"alpha": {absPath(t, "alpha.go")},
+						"pair":  {absPath(t, "alpha.go"), absPath(t, "alpha.txt")},
+					},
+				},
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go"), absPath(t, "alpha.txt")},
+				},
+			},
+		}, // DeleteAliases tests
+		{
+			name: "error if no arguments",
+			etc: &command.ExecuteTestCase{
+				Args:       []string{"d"},
+				WantStderr: []string{"not enough arguments"},
+				WantErr:    fmt.Errorf("not enough arguments"),
+			},
+		}, {
+			name: "ignores unknown alias",
+			etc: &command.ExecuteTestCase{
+				Args: []string{"d", "salt"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						"ALIAS": command.StringListValue("salt"),
+					},
+				},
+				WantStderr: []string{`Alias group has no aliases yet.`},
+				WantErr:    fmt.Errorf(`Alias group has no aliases yet.`),
+			},
+		}, {
+			name: "deletes existing alias",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"salt": {path("compounds", "sodiumChloride")},
+					},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				WantStdout: []string{
+					fmt.Sprintf("Deleted alias salt: %s", path("compounds", "sodiumChloride")),
+				},
+				Args: []string{"d", "salt"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						"ALIAS": command.StringListValue("salt"),
+					},
+				},
+			},
+			want: &Emacs{
+				Aliases: map[string]map[string][]string{fileAliaserName: {}},
+			},
+		}, {
+			name: "deleting alias clears its new file flag and use count",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"nf":   {absPath(t, "newFile.txt")},
+						"salt": {path("compounds", "sodiumChloride")},
+					},
+				},
+				NewFileAliases: map[string]map[string]bool{
+					fileAliaserName: {
+						"nf": true,
+					},
+				},
+				AliasUses: map[string]map[string]int{
+					fileAliaserName: {
+						"nf":   3,
+						"salt": 2,
+					},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				WantStdout: []string{
+					fmt.Sprintf("Deleted alias nf: %s", absPath(t, "newFile.txt")),
+				},
+				Args: []string{"d", "nf"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						"ALIAS": command.StringListValue("nf"),
+					},
+				},
+			},
+			want: &Emacs{
+				Aliases: map[string]map[string][]string{fileAliaserName: {
+					"salt": {path("compounds", "sodiumChloride")},
+				}},
+				NewFileAliases: map[string]map[string]bool{fileAliaserName: {}},
+				AliasUses:      map[string]map[string]int{fileAliaserName: {"salt": 2}},
+			},
+		}, {
+			name: "handles multiple missing and present",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"salt": {path("compounds", "sodiumChloride")},
+						"city": {path("catan", "oreAndWheat")},
+						"4":    {"2+2"},
+					},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				WantStdout: []string{
+					`Deleted alias 4: 2+2`,
+					fmt.Sprintf("Deleted alias salt: %s", path("compounds", "sodiumChloride")),
+				},
+				Args: []string{"d", "salt", "settlement", "5", "4"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						"ALIAS": command.StringListValue("salt", "settlement", "5", "4"),
+					},
+				},
+				WantStderr: []string{
+					`Alias "settlement" does not exist`,
+					`Alias "5" does not exist`,
+				},
+			},
+			want: &Emacs{
+				Aliases: map[string]map[string][]string{fileAliaserName: {
+					"city": {path("catan", "oreAndWheat")},
+				}},
+			},
+		}, {
+			name: "short quiet flag suppresses delete summary",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"salt": {path("compounds", "sodiumChloride")},
+					},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"d", "-q", "salt"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						"ALIAS":          command.StringListValue("salt"),
+						quietFlag.Name(): command.BoolValue(true),
+					},
+				},
+			},
+			want: &Emacs{
+				Aliases: map[string]map[string][]string{fileAliaserName: {}},
+			},
+		}, {
+			name: "deletes all aliases",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"salt": {path("compounds", "sodiumChloride")},
+						"city": {path("catan", "oreAndWheat")},
+					},
+					"docs": {
+						"r": {path("alpha.txt")},
+					},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"d", "--all", "-f"},
+				WantStdout: []string{
+					fmt.Sprintf("Deleted alias city: %s", path("catan", "oreAndWheat")),
+					fmt.Sprintf("Deleted alias salt: %s", path("compounds", "sodiumChloride")),
+					"Deleted 2 alias(es).",
+				},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						allFlag.Name():   command.BoolValue(true),
+						forceFlag.Name(): command.BoolValue(true),
+					},
+				},
+			},
+			want: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {},
+					"docs": {
+						"r": {path("alpha.txt")},
+					},
+				},
+			},
+		}, {
+			name: "deleting all aliases clears their new file flags and use counts",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"nf": {absPath(t, "newFile.txt")},
+					},
+					"docs": {
+						"r": {path("alpha.txt")},
+					},
+				},
+				NewFileAliases: map[string]map[string]bool{
+					fileAliaserName: {
+						"nf": true,
+					},
+					"docs": {
+						"r": true,
+					},
+				},
+				AliasUses: map[string]map[string]int{
+					fileAliaserName: {
+						"nf": 1,
+					},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"d", "--all", "-f"},
+				WantStdout: []string{
+					fmt.Sprintf("Deleted alias nf: %s", absPath(t, "newFile.txt")),
+					"Deleted 1 alias(es).",
+				},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						allFlag.Name():   command.BoolValue(true),
+						forceFlag.Name(): command.BoolValue(true),
+					},
+				},
+			},
+			want: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {},
+					"docs": {
+						"r": {path("alpha.txt")},
+					},
+				},
+				NewFileAliases: map[string]map[string]bool{
+					fileAliaserName: {},
+					"docs": {
+						"r": true,
+					},
+				},
+				AliasUses: map[string]map[string]int{fileAliaserName: {}},
+			},
+		}, {
+			name: "deletes all aliases in group",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"salt": {path("compounds", "sodiumChloride")},
+					},
+					"docs": {
+						"r": {path("alpha.txt")},
+					},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"-G", "docs", "d", "-a", "-f"},
+				WantStdout: []string{
+					fmt.Sprintf("Deleted alias r: %s", path("alpha.txt")),
+					"Deleted 1 alias(es).",
+				},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						groupFlag.Name(): command.StringValue("docs"),
+						allFlag.Name():   command.BoolValue(true),
+						forceFlag.Name(): command.BoolValue(true),
+					},
+				},
+			},
+			want: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"salt": {path("compounds", "sodiumChloride")},
+					},
+					"docs": {},
+				},
+			},
+		}, {
+			name: "quiet flag suppresses delete all summary",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"salt": {path("compounds", "sodiumChloride")},
+					},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"-q", "d", "-a", "-f"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						quietFlag.Name(): command.BoolValue(true),
+						allFlag.Name():   command.BoolValue(true),
+						forceFlag.Name(): command.BoolValue(true),
+					},
+				},
+			},
+			want: &Emacs{
+				Aliases: map[string]map[string][]string{fileAliaserName: {}},
+			},
+		}, {
+			name: "delete all requires force flag",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"salt": {path("compounds", "sodiumChloride")},
+					},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args:       []string{"d", "--all"},
+				WantStderr: []string{`include "force" flag to delete all aliases in group "fileAliases"`},
+				WantErr:    fmt.Errorf(`include "force" flag to delete all aliases in group "fileAliases"`),
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						allFlag.Name(): command.BoolValue(true),
+					},
+				},
+			},
+		}, {
+			name: "delete all fails if aliases are provided",
+			etc: &command.ExecuteTestCase{
+				Args:       []string{"d", "--all", "salt"},
+				WantStderr: []string{`aliases can't be provided with the "all" flag`},
+				WantErr:    fmt.Errorf(`aliases can't be provided with the "all" flag`),
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						allFlag.Name(): command.BoolValue(true),
+					},
+				},
+			},
+		}, // ListAliases tests
+		{
+			name: "error when too many arguments for list",
+			etc: &command.ExecuteTestCase{
+				Args:       []string{"l", "extra"},
+				WantStderr: []string{"Unprocessed extra args: [extra]"},
+				WantErr:    fmt.Errorf("Unprocessed extra args: [extra]"),
+			},
+		}, {
+			name: "no output for nil aliases",
+			etc: &command.ExecuteTestCase{
+				Args: []string{"l"},
+			},
+		}, {
+			name: "no output for empty aliases",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"l"},
+			},
+		}, {
+			name: "proper output for aliases",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"salt": {"compounds/sodiumChloride"},
+						"city": {"catan", "oreAndWheat"},
+						"4":    {"2+2"},
+					},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"l"},
+				WantStdout: []string{
+					"4: 2+2",
+					"city: catan oreAndWheat",
+					"salt: compounds/sodiumChloride",
+				},
+			},
+		}, {
+			name: "check annotates missing alias files",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"salt":   {absPath(t, "compounds", "potassiumChloride"), "12"},
+						"uno":    {absPath(t, "alpha.go")},
+						"duo":    {absPath(t, "alpha.txt"), absPath(t, "beta.txt")},
+						"logs":   {"!journalctl -u myservice"},
+						"remote": {"/ssh:host:/etc/hosts"},
+					},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"l", "--check"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						checkFlag.Name(): command.BoolValue(true),
+					},
+				},
+				WantStdout: []string{
+					fmt.Sprintf("duo: %s %s (missing)", absPath(t, "alpha.txt"), absPath(t, "beta.txt")),
+					"logs: !journalctl -u myservice",
+					"remote: /ssh:host:/etc/hosts",
+					fmt.Sprintf("salt: %s (missing) 12", absPath(t, "compounds", "potassiumChloride")),
+					fmt.Sprintf("uno: %s", absPath(t, "alpha.go")),
+				},
+			},
+		}, {
+			name: "count includes alias uses",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"salt": {"compounds/sodiumChloride"},
+						"city": {"catan/oreAndWheat"},
+					},
+				},
+				AliasUses: map[string]map[string]int{
+					fileAliaserName: {"salt": 3},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"l", "--count"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						countFlag.Name(): command.BoolValue(true),
+					},
+				},
+				WantStdout: []string{
+					"city: catan/oreAndWheat (used 0)",
+					"salt: compounds/sodiumChloride (used 3)",
+				},
+			},
+		}, {
+			name: "counts existing alias uses",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"salt": {path("compounds", "sodiumChloride")},
+					},
+				},
+				AliasUses: map[string]map[string]int{
+					fileAliaserName: {"salt": 3},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"salt"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue(absPath(t, "compounds", "sodiumChloride")),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system %s", absPath(t, "compounds", "sodiumChloride")),
+					},
+				},
+			},
+			want: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"salt": {path("compounds", "sodiumChloride")},
+					},
+				},
+				AliasUses: map[string]map[string]int{
+					fileAliaserName: {"salt": 4},
+				},
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "compounds", "sodiumChloride")},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "compounds", "sodiumChloride")}, Args: []string{absPath(t, "compounds", "sodiumChloride")}},
+				},
+			},
+		}, {
+			name: "doesn't count alias uses if files aren't opened",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"salt": {path("compounds", "potassiumChloride")},
+					},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"salt"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue(absPath(t, "compounds", "potassiumChloride")),
+					},
+				},
+				WantStderr: []string{fmt.Sprintf(`file %q does not exist; include "new" flag to create it`, absPath(t, "compounds", "potassiumChloride"))},
+				WantErr:    fmt.Errorf(`file %q does not exist; include "new" flag to create it`, absPath(t, "compounds", "potassiumChloride")),
+			},
+			want: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"salt": {path("compounds", "potassiumChloride")},
+					},
+				},
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "compounds", "potassiumChloride")},
+				},
+			},
+		}, {
+			name: "lists aliases in all groups",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"salt": {"compounds/sodiumChloride"},
+					},
+					"docs": {
+						"readme": {"README.md"},
+					},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"l"},
+				WantStdout: []string{
+					"[fileAliases]",
+					"salt: compounds/sodiumChloride",
+					"[docs]",
+					"readme: README.md",
+				},
+			},
+		}, {
+			name: "lists aliases in group",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"salt": {"compounds/sodiumChloride"},
+					},
+					"docs": {
+						"readme": {"README.md"},
+					},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"--group", "docs", "l"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						groupFlag.Name(): command.StringValue("docs"),
+					},
+				},
+				WantStdout: []string{
+					"readme: README.md",
+				},
+			},
+		}, {
+			name: "adds alias to group",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"salt": {path("compounds", "sodiumChloride")},
+					},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"-G", "docs", "a", "salt", path("alpha.txt")},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						"ALIAS":          command.StringValue("salt"),
+						groupFlag.Name(): command.StringValue("docs"),
+						emacsArg:         command.StringListValue(absPath(t, "alpha.txt")),
+					},
+				},
+				WantStdout: []string{
+					fmt.Sprintf("Added alias salt: %s", absPath(t, "alpha.txt")),
+				},
+			},
+			want: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"salt": {path("compounds", "sodiumChloride")},
+					},
+					"docs": {
+						"salt": {absPath(t, "alpha.txt")},
+					},
+				},
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.txt")},
+				},
+			},
+		}, {
+			name: "adds alias that references other aliases",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"salt": {absPath(t, "compounds", "sodiumChloride")},
+						"city": {absPath(t, "catan", "oreAndWheat"), "12"},
+					},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args:       []string{"a", "combo", "salt", "city"},
+				WantStdout: []string{"Added alias combo: salt city"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						"ALIAS":  command.StringValue("combo"),
+						emacsArg: command.StringListValue(absPath(t, "compounds", "sodiumChloride"), absPath(t, "catan", "oreAndWheat")),
+					},
+				},
+			},
+			want: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"salt":  {absPath(t, "compounds", "sodiumChloride")},
+						"city":  {absPath(t, "catan", "oreAndWheat"), "12"},
+						"combo": {"salt", "city"},
+					},
+				},
+				Caches: map[string][]string{
+					cacheName: {"salt", "city"},
+				},
+			},
+		}, {
+			name: "opens aliases that reference other aliases",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"salt":  {absPath(t, "compounds", "sodiumChloride")},
+						"city":  {absPath(t, "catan", "oreAndWheat"), "12"},
+						"combo": {"salt", "city"},
+					},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"combo"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue(absPath(t, "compounds", "sodiumChloride"), absPath(t, "catan", "oreAndWheat")),
+						lineArg:  command.IntListValue(0, 12),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system +12 %s %s %s", absPath(t, "catan", "oreAndWheat"), absPath(t, "compounds", "sodiumChloride"), splitEval(absPath(t, "catan", "oreAndWheat"))),
+					},
+				},
+			},
+			want: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"salt":  {absPath(t, "compounds", "sodiumChloride")},
+						"city":  {absPath(t, "catan", "oreAndWheat"), "12"},
+						"combo": {"salt", "city"},
+					},
+				},
+				AliasUses: map[string]map[string]int{fileAliaserName: {"city": 1, "combo": 1, "salt": 1}},
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "compounds", "sodiumChloride"), absPath(t, "catan", "oreAndWheat"), "12"},
+				},
+				History: []*historyEntry{
+					{
+						Files: []string{absPath(t, "compounds", "sodiumChloride"), absPath(t, "catan", "oreAndWheat")},
+						Args:  []string{absPath(t, "compounds", "sodiumChloride"), absPath(t, "catan", "oreAndWheat"), "12"},
+					},
+				},
+			},
+		}, {
+			name: "fails to add alias with cyclic references",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"x": {"y"},
+						"y": {"x"},
+					},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"a", "z", "x"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						"ALIAS": command.StringValue("z"),
+					},
+				},
+				WantStderr: []string{"alias cycle detected: x -> y -> x"},
+				WantErr:    fmt.Errorf("alias cycle detected: x -> y -> x"),
+			},
+			want: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"x": {"y"},
+						"y": {"x"},
+					},
+				},
+				Caches: map[string][]string{
+					cacheName: {"x"},
+				},
+			},
+		}, {
+			name: "fails to open alias with cyclic references",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"x": {"y"},
+						"y": {"x"},
+					},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args:       []string{"x"},
+				WantStderr: []string{`alias cycle detected for "y"`},
+				WantErr:    fmt.Errorf(`alias cycle detected for "y"`),
+			},
+			want: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"x": {"y"},
+						"y": {"x"},
+					},
+				},
+				Caches: map[string][]string{
+					cacheName: {"y"},
+				},
+			},
+		}, {
+			name: "opens alias from group",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"salt": {path("compounds", "sodiumChloride")},
+					},
+					"docs": {
+						"salt": {absPath(t, "alpha.txt")},
+					},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"-G", "docs", "salt"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						groupFlag.Name(): command.StringValue("docs"),
+						emacsArg:         command.StringListValue(absPath(t, "alpha.txt")),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system %s", absPath(t, "alpha.txt")),
+					},
+				},
+			},
+			want: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"salt": {path("compounds", "sodiumChloride")},
+					},
+					"docs": {
+						"salt": {absPath(t, "alpha.txt")},
+					},
+				},
+				AliasUses: map[string]map[string]int{"docs": {"salt": 1}},
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.txt")},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.txt")}, Args: []string{absPath(t, "alpha.txt")}},
+				},
+			},
+		}, {
+			name: "cds into aliased directory",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"t": {absPath(t, "dirA")},
+					},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"t"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue(absPath(t, "dirA")),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{fmt.Sprintf("cd %s", absPath(t, "dirA"))},
+				},
+			},
+			want: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"t": {absPath(t, "dirA")},
+					},
+				},
+				AliasUses: map[string]map[string]int{fileAliaserName: {"t": 1}},
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "dirA")},
+				},
+			},
+		}, {
+			name: "deletes alias from group",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"salt": {path("compounds", "sodiumChloride")},
+					},
+					"docs": {
+						"salt": {absPath(t, "alpha.txt")},
+					},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"d", "salt", "--group", "docs"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						"ALIAS":          command.StringListValue("salt"),
+						groupFlag.Name(): command.StringValue("docs"),
+					},
+				},
+				WantStdout: []string{
+					fmt.Sprintf("Deleted alias salt: %s", absPath(t, "alpha.txt")),
+				},
+			},
+			want: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"salt": {path("compounds", "sodiumChloride")},
+					},
+					"docs": {},
+				},
+			},
+		}, // GetAlias
+		{
+			name: "GetAlias requires alias",
+			etc: &command.ExecuteTestCase{
+				Args: []string{"g"},
+				WantStderr: []string{
+					fmt.Sprintf("not enough arguments"),
+				},
+				WantErr: fmt.Errorf("not enough arguments"),
+			},
+		}, {
+			name: "GetAlias fails if alias group does not exist",
+			etc: &command.ExecuteTestCase{
+				Args: []string{"g", "salt"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						"ALIAS": command.StringListValue("salt"),
+					},
+				},
+				WantStderr: []string{
+					`No aliases exist for alias type "fileAliases"`,
+				},
+				WantErr: fmt.Errorf(`No aliases exist for alias type "fileAliases"`),
+			},
+		}, {
+			name: "GetAlias fails if alias does not exist",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"ot": []string{"h", "e", "r"},
+					},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"g", "salt"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						"ALIAS": command.StringListValue("salt"),
+					},
+				},
+				WantStderr: []string{
+					`Alias "salt" does not exist`,
+				},
+			},
+		}, {
+			name: "GetAlias works",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"salt": {"compounds/sodiumChloride"},
+						"city": {"catan/oreAndWheat"},
+						"4":    {"2+2"},
+					}},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"g", "salt"},
+				WantStdout: []string{
+					"salt: compounds/sodiumChloride",
+				},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						"ALIAS": command.StringListValue("salt"),
+					},
+				},
+			},
+		}, // SearchAliases
+		{
+			name: "SearchAlias requires regexp",
+			etc: &command.ExecuteTestCase{
+				Args: []string{"s"},
+				WantStderr: []string{
+					fmt.Sprintf("not enough arguments"),
+				},
+				WantErr: fmt.Errorf("not enough arguments"),
+			},
+		}, {
+			name: "SearchAlias requires valid regexp",
+			etc: &command.ExecuteTestCase{
+				Args: []string{"s", "[a-9]"},
+				WantStderr: []string{
+					"Invalid regexp: error parsing regexp: invalid character class range: `a-9`",
+				},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						"regexp": command.StringListValue("[a-9]"),
+					},
+				},
+				WantErr: fmt.Errorf("Invalid regexp: error parsing regexp: invalid character class range: `a-9`"),
+			},
+		}, {
+			name: "SearchAlias works",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"water": {"liquids/compounds/hydrogenDioxide"},
+						"salt":  {"compounds/sodiumChloride"},
+						"city":  {"catan/oreAndWheat"},
+						"4":     {"2+2"},
+					},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"s", "compounds"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						"regexp": command.StringListValue("compounds"),
+					},
+				},
+				WantStdout: []string{
+					"salt: compounds/sodiumChloride",
+					"water: liquids/compounds/hydrogenDioxide",
+				},
+			},
+		}, {
+			name: "SearchAlias ranks results",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"water": {"liquids/compounds/hydrogenDioxide"},
+						"salt":  {"compounds/sodiumChloride"},
+						"mix":   {"compounds/moreCompounds"},
+						"city":  {"catan/oreAndWheat"},
+					},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"s", "--rank", "(?i)compounds"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						rankFlag.Name(): command.BoolValue(true),
+						regexpArg:       command.StringListValue("(?i)compounds"),
+					},
+				},
+				WantStdout: []string{
+					"mix: compounds/moreCompounds",
+					"salt: compounds/sodiumChloride",
+					"water: liquids/compounds/hydrogenDioxide",
+				},
+			},
+		}, {
+			name: "SearchAlias ranks ties alphabetically",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"ab": {"xyz"},
+						"aa": {"xyz"},
+						"ba": {"abc"},
+					},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"s", "-R", "x", "z"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						rankFlag.Name(): command.BoolValue(true),
+						regexpArg:       command.StringListValue("x", "z"),
+					},
+				},
+				WantStdout: []string{
+					"aa: xyz",
+					"ab: xyz",
+				},
+			},
+		}, {
+			name: "ranked SearchAlias requires valid regexp",
+			etc: &command.ExecuteTestCase{
+				Args: []string{"s", "--rank", "[a-9]"},
+				WantStderr: []string{
+					"Invalid regexp: error parsing regexp: invalid character class range: `a-9`",
+				},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						rankFlag.Name(): command.BoolValue(true),
+						regexpArg:       command.StringListValue("[a-9]"),
+					},
+				},
+				WantErr: fmt.Errorf("Invalid regexp: error parsing regexp: invalid character class range: `a-9`"),
+			},
+		},
+		// Alias elisp
+		{
+			name: "el lists multi-file aliases",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"salt":  {"/compounds/sodiumChloride", "12"},
+						"catan": {"/catan/oreAndWheat", "/catan/sheep", "3:4"},
+					},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"el"},
+				WantStdout: []string{strings.Join([]string{
+					"(setq aliasMap",
+					"#s(hash-table",
+					"size 2",
+					"test equal",
+					"data (",
+					`"catan" ("/catan/oreAndWheat" "/catan/sheep")`,
+					`"salt" "/compounds/sodiumChloride"`,
+					")))",
+					"",
+					`(global-set-key (kbd "C-x C-j") (lambda () (interactive)`,
+					`(setq a (read-string "Alias: "))`,
+					`(setq v (gethash a aliasMap))`,
+					`(cond ((stringp v) (find-file v)) (v (mapc (quote find-file) v)) (t (message "Unknown alias: %s" a)))`,
+					"))",
+				}, "\n")},
+			},
+		},
+		// Monitor flag
+		{
+			name: "monitor flag is ignored in terminal mode",
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("alpha.go"), "--monitor", "1"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg:           command.StringListValue(absPath(t, "alpha.go")),
+						monitorFlag.Name(): command.IntValue(1),
+					},
+				},
+				WantStderr: []string{`ignoring "monitor" flag since frames are only positioned in GUI mode`},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system %s", absPath(t, "alpha.go")),
+					},
+				},
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go"), "--monitor", "1"},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go")}, Args: []string{absPath(t, "alpha.go"), "--monitor", "1"}},
+				},
+			},
+		}, {
+			name: "monitor flag must be non-negative",
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("alpha.go"), "-m", "-1"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						monitorFlag.Name(): command.IntValue(-1),
+					},
+				},
+				WantStderr: []string{"validation failed: [IntNonNegative] value isn't non-negative"},
+				WantErr:    fmt.Errorf("validation failed: [IntNonNegative] value isn't non-negative"),
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {path("alpha.go"), "-m", "-1"},
+				},
+			},
+		},
+		// New frame flag
+		{
+			name: "new-frame flag opens a gui frame in daemon mode",
+			e: &Emacs{
+				DaemonMode: true,
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("alpha.go"), "-c", "--monitor", "1"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg:            command.StringListValue(absPath(t, "alpha.go")),
+						newFrameFlag.Name(): command.BoolValue(true),
+						monitorFlag.Name():  command.IntValue(1),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf(`emacsclient -c -e '(progn %s(find-file "%s"))'`, monitorElisp(1), absPath(t, "alpha.go")),
+					},
+				},
+			},
+			want: &Emacs{
+				DaemonMode: true,
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go"), "-c", "--monitor", "1"},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go")}, Args: []string{absPath(t, "alpha.go"), "-c", "--monitor", "1"}},
+				},
+			},
+		}, {
+			name: "new-frame flag is ignored in basic mode",
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("alpha.go"), "--new-frame"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg:            command.StringListValue(absPath(t, "alpha.go")),
+						newFrameFlag.Name(): command.BoolValue(true),
+					},
+				},
+				WantStderr: []string{`ignoring "new-frame" flag since new frames are only created in daemon mode`},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system %s", absPath(t, "alpha.go")),
+					},
+				},
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go"), "--new-frame"},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go")}, Args: []string{absPath(t, "alpha.go"), "--new-frame"}},
+				},
+			},
+		},
+		// Verbose flag
+		{
+			name: "verbose flag prints the number of files",
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("alpha.go"), path("alpha.txt"), "-v"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg:           command.StringListValue(absPath(t, "alpha.go"), absPath(t, "alpha.txt")),
+						verboseFlag.Name(): command.BoolValue(true),
+					},
+				},
+				WantStdout: []string{"Opening 2 files"},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system %s %s %s", absPath(t, "alpha.txt"), absPath(t, "alpha.go"), splitEval(absPath(t, "alpha.txt"))),
+					},
+				},
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go"), absPath(t, "alpha.txt"), "-v"},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go"), absPath(t, "alpha.txt")}, Args: []string{absPath(t, "alpha.go"), absPath(t, "alpha.txt"), "-v"}},
+				},
+			},
+		}, {
+			name: "verbose flag prints a single file",
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("alpha.go"), "--verbose"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg:           command.StringListValue(absPath(t, "alpha.go")),
+						verboseFlag.Name(): command.BoolValue(true),
+					},
+				},
+				WantStdout: []string{"Opening 1 file"},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system %s", absPath(t, "alpha.go")),
+					},
+				},
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go"), "--verbose"},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go")}, Args: []string{absPath(t, "alpha.go"), "--verbose"}},
+				},
+			},
+		},
+		// No wait flag
+		{
+			name: "nowait flag doesn't wait for the daemon client",
+			e: &Emacs{
+				DaemonMode: true,
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("alpha.go"), "--nowait"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg:          command.StringListValue(absPath(t, "alpha.go")),
+						noWaitFlag.Name(): command.BoolValue(true),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf(`emacsclient -n -t -e '(progn (find-file "%s"))'`, absPath(t, "alpha.go")),
+					},
+				},
+			},
+			want: &Emacs{
+				DaemonMode: true,
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go"), "--nowait"},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go")}, Args: []string{absPath(t, "alpha.go"), "--nowait"}},
+				},
+			},
+		}, {
+			name: "daemon client waits by default",
+			e: &Emacs{
+				DaemonMode: true,
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("alpha.go")},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue(absPath(t, "alpha.go")),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf(`emacsclient -t -e '(progn (find-file "%s"))'`, absPath(t, "alpha.go")),
+					},
+				},
+			},
+			want: &Emacs{
+				DaemonMode: true,
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go")},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go")}, Args: []string{absPath(t, "alpha.go")}},
+				},
+			},
+		}, {
+			name: "nowait flag is ignored in basic mode",
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("alpha.go"), "-N"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg:          command.StringListValue(absPath(t, "alpha.go")),
+						noWaitFlag.Name(): command.BoolValue(true),
+					},
+				},
+				WantStderr: []string{`ignoring "nowait" flag since only the daemon client can return immediately`},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system %s", absPath(t, "alpha.go")),
+					},
+				},
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go"), "-N"},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go")}, Args: []string{absPath(t, "alpha.go"), "-N"}},
+				},
+			},
+		},
+		// No history
+		{
+			name: "no-history flag doesn't update cache or history",
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("alpha.go"), "--no-history"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg:             command.StringListValue(absPath(t, "alpha.go")),
+						noHistoryFlag.Name(): command.BoolValue(true),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system %s", absPath(t, "alpha.go")),
+					},
+				},
+			},
+		}, {
+			name: "short no-history flag keeps existing cache and history",
+			e: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.txt")},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.txt")}},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"-H", path("alpha.go"), "3"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg:             command.StringListValue(absPath(t, "alpha.go")),
+						lineArg:              command.IntListValue(3),
+						noHistoryFlag.Name(): command.BoolValue(true),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system +3 %s", absPath(t, "alpha.go")),
+					},
+				},
+			},
+		}, {
+			name: "no-history flag after separator is passed to emacs",
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("alpha.go"), "--", "-H"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg:       command.StringListValue(absPath(t, "alpha.go")),
+						passthroughArg: command.StringListValue("-H"),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system %s -H", absPath(t, "alpha.go")),
+					},
+				},
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go"), "--", "-H"},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go")}, Args: []string{absPath(t, "alpha.go"), "--", "-H"}},
+				},
+			},
+		},
+		// Copy path
+		{
+			name: "copies paths to clipboard",
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("alpha.go"), path("alpha.txt"), "--copy-path"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg:            command.StringListValue(absPath(t, "alpha.go"), absPath(t, "alpha.txt")),
+						copyPathFlag.Name(): command.BoolValue(true),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system %s %s %s", absPath(t, "alpha.txt"), absPath(t, "alpha.go"), splitEval(absPath(t, "alpha.txt"))),
+					},
+				},
+			},
+			wantClipboard: []string{
+				fmt.Sprintf("%s\n%s", absPath(t, "alpha.go"), absPath(t, "alpha.txt")),
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go"), absPath(t, "alpha.txt"), "--copy-path"},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go"), absPath(t, "alpha.txt")}, Args: []string{absPath(t, "alpha.go"), absPath(t, "alpha.txt"), "--copy-path"}},
+				},
+			},
+		}, {
+			name: "copies path to clipboard in daemon mode",
+			e: &Emacs{
+				DaemonMode: true,
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("alpha.go"), "-C"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg:            command.StringListValue(absPath(t, "alpha.go")),
+						copyPathFlag.Name(): command.BoolValue(true),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf(`emacsclient -t -e '(progn (find-file "%s"))'`, absPath(t, "alpha.go")),
+					},
+				},
+			},
+			wantClipboard: []string{absPath(t, "alpha.go")},
+			want: &Emacs{
+				DaemonMode: true,
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go"), "-C"},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go")}, Args: []string{absPath(t, "alpha.go"), "-C"}},
+				},
+			},
+		},
+		// Filesystem
+		{
+			name: "opens glob matches from the filesystem",
+			globs: map[string][]string{
+				"/project/*.go": {"/project/main.go", "/project/util.go"},
+			},
+			fileInfos: map[string]os.FileInfo{
+				"/project/main.go": fakeFileInfo{mode: 0644},
+				"/project/util.go": fakeFileInfo{mode: 0644},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"/project/*.go"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue("/project/*.go"),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system /project/util.go /project/main.go %s", splitEval("/project/util.go")),
+					},
+				},
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {"/project/*.go"},
+				},
+				History: []*historyEntry{
+					{Files: []string{"/project/main.go", "/project/util.go"}, Args: []string{"/project/*.go"}},
+				},
+			},
+		}, {
+			name: "fails if a file was removed from the filesystem",
+			fileInfos: map[string]os.FileInfo{
+				absPath(t, "alpha.go"): nil,
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("alpha.go")},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue(absPath(t, "alpha.go")),
+					},
+				},
+				WantStderr: []string{fmt.Sprintf(`file %q does not exist; include "new" flag to create it`, absPath(t, "alpha.go"))},
+				WantErr:    fmt.Errorf(`file %q does not exist; include "new" flag to create it`, absPath(t, "alpha.go")),
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go")},
+				},
+			},
+		},
+		// List files
+		{
+			name: "opens files from list file",
+			etc: &command.ExecuteTestCase{
+				Args: []string{"@" + path("fileList.txt")},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue("@" + path("fileList.txt")),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system %s +3 %s +12 %s", absPath(t, "other.txt"), absPath(t, "alpha.txt"), absPath(t, "alpha.go")),
+					},
+				},
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {"@" + path("fileList.txt")},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go"), absPath(t, "alpha.txt"), absPath(t, "other.txt")}, Args: []string{"@" + path("fileList.txt")}},
+				},
+			},
+		}, {
+			name: "opens list file with other file",
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("luckyNumberThree"), "7", "@" + path("fileList.txt")},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue(absPath(t, "luckyNumberThree"), "@"+path("fileList.txt")),
+						lineArg:  command.IntListValue(7),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system %s +3 %s +12 %s +7 %s", absPath(t, "other.txt"), absPath(t, "alpha.txt"), absPath(t, "alpha.go"), absPath(t, "luckyNumberThree")),
+					},
+				},
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "luckyNumberThree"), "7", "@" + path("fileList.txt")},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "luckyNumberThree"), absPath(t, "alpha.go"), absPath(t, "alpha.txt"), absPath(t, "other.txt")}, Args: []string{absPath(t, "luckyNumberThree"), "7", "@" + path("fileList.txt")}},
+				},
+			},
+		}, {
+			name: "opens files from args file",
+			etc: &command.ExecuteTestCase{
+				Args: []string{"--args-file", path("fileList.txt")},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						argsFileFlag.Name(): command.StringValue(absPath(t, "fileList.txt")),
+						emacsArg:            command.StringListValue("@" + absPath(t, "fileList.txt")),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system %s +3 %s +12 %s", absPath(t, "other.txt"), absPath(t, "alpha.txt"), absPath(t, "alpha.go")),
+					},
+				},
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {"--args-file", absPath(t, "fileList.txt")},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go"), absPath(t, "alpha.txt"), absPath(t, "other.txt")}, Args: []string{"--args-file", absPath(t, "fileList.txt")}},
+				},
+			},
+		}, {
+			name: "opens args file with other file",
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("luckyNumberThree"), "7", "-F", path("fileList.txt")},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						argsFileFlag.Name(): command.StringValue(absPath(t, "fileList.txt")),
+						emacsArg:            command.StringListValue(absPath(t, "luckyNumberThree"), "@"+absPath(t, "fileList.txt")),
+						lineArg:             command.IntListValue(7),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system %s +3 %s +12 %s +7 %s", absPath(t, "other.txt"), absPath(t, "alpha.txt"), absPath(t, "alpha.go"), absPath(t, "luckyNumberThree")),
+					},
+				},
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "luckyNumberThree"), "7", "-F", absPath(t, "fileList.txt")},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "luckyNumberThree"), absPath(t, "alpha.go"), absPath(t, "alpha.txt"), absPath(t, "other.txt")}, Args: []string{absPath(t, "luckyNumberThree"), "7", "-F", absPath(t, "fileList.txt")}},
+				},
+			},
+		}, {
+			name: "fails if args file does not exist",
+			etc: &command.ExecuteTestCase{
+				Args: []string{"--args-file", path("missing.txt")},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						argsFileFlag.Name(): command.StringValue(absPath(t, "missing.txt")),
+						emacsArg:            command.StringListValue("@" + absPath(t, "missing.txt")),
+					},
+				},
+				WantStderr: []string{fmt.Sprintf("failed to open list file: open %s: no such file or directory", absPath(t, "missing.txt"))},
+				WantErr:    fmt.Errorf("failed to open list file: open %s: no such file or directory", absPath(t, "missing.txt")),
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {"--args-file", absPath(t, "missing.txt")},
+				},
+			},
+		}, {
+			name: "fails if list file does not exist",
+			etc: &command.ExecuteTestCase{
+				Args: []string{"@" + path("missing.txt")},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue("@" + path("missing.txt")),
+					},
+				},
+				WantStderr: []string{fmt.Sprintf("failed to open list file: open %s: no such file or directory", path("missing.txt"))},
+				WantErr:    fmt.Errorf("failed to open list file: open %s: no such file or directory", path("missing.txt")),
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {"@" + path("missing.txt")},
+				},
+			},
+		}, {
+			name: "opens files from stdin",
+			stdin: strings.Join([]string{
+				path("alpha.go"),
+				"",
+				path("alpha.txt") + ":3",
+				"  ",
+				path("other.txt"),
+			}, "\n"),
+			etc: &command.ExecuteTestCase{
+				Args: []string{"-"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue("-"),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system %s +3 %s %s", absPath(t, "other.txt"), absPath(t, "alpha.txt"), absPath(t, "alpha.go")),
+					},
+				},
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {"-"},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go"), absPath(t, "alpha.txt"), absPath(t, "other.txt")}, Args: []string{"-"}},
+				},
+			},
+		}, {
+			name: "opens stdin files with other file in daemon mode",
+			e: &Emacs{
+				DaemonMode: true,
+			},
+			stdin: path("alpha.txt") + "\n",
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("alpha.go"), "12", "-"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue(absPath(t, "alpha.go"), "-"),
+						lineArg:  command.IntListValue(12),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf(`emacsclient -t -e '(progn (find-file "%s")(goto-line 12)(find-file-other-window "%s")(other-window 1))'`, absPath(t, "alpha.go"), absPath(t, "alpha.txt")),
+					},
+				},
+			},
+			want: &Emacs{
+				DaemonMode: true,
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go"), "12", "-"},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go"), absPath(t, "alpha.txt")}, Args: []string{absPath(t, "alpha.go"), "12", "-"}},
+				},
+			},
+		}, {
+			name:  "fails if a stdin file does not exist",
+			stdin: path("missing.txt"),
+			etc: &command.ExecuteTestCase{
+				Args: []string{"-"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue("-"),
+					},
+				},
+				WantStderr: []string{fmt.Sprintf(`file %q does not exist; include "new" flag to create it`, absPath(t, "missing.txt"))},
+				WantErr:    fmt.Errorf(`file %q does not exist; include "new" flag to create it`, absPath(t, "missing.txt")),
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {"-"},
+				},
+			},
+		},
+		// Read-only detection
+		{
+			name: "opens unwritable files read-only",
+			fileInfos: map[string]os.FileInfo{
+				absPath(t, "alpha.go"): fakeFileInfo{mode: 0444},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("alpha.go"), "12", path("alpha.txt")},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue(absPath(t, "alpha.go"), absPath(t, "alpha.txt")),
+						lineArg:  command.IntListValue(12),
+					},
+				},
+				WantStderr: []string{
+					fmt.Sprintf(`file %q is not writable so opening it read-only (sudo is needed to edit it); include "writable" flag to open it normally`, absPath(t, "alpha.go")),
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf(`emacs --no-window-system %s --eval '(progn (find-file-read-only "%s") (goto-line 12))' %s`, absPath(t, "alpha.txt"), absPath(t, "alpha.go"), splitEval(absPath(t, "alpha.txt"))),
+					},
+				},
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go"), "12", absPath(t, "alpha.txt")},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go"), absPath(t, "alpha.txt")}, Args: []string{absPath(t, "alpha.go"), "12", absPath(t, "alpha.txt")}},
+				},
+			},
+		}, {
+			name: "opens unwritable files read-only in daemon mode",
+			e: &Emacs{
+				DaemonMode: true,
+			},
+			fileInfos: map[string]os.FileInfo{
+				absPath(t, "alpha.txt"): fakeFileInfo{mode: 0444},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("alpha.go"), path("alpha.txt")},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue(absPath(t, "alpha.go"), absPath(t, "alpha.txt")),
+					},
+				},
+				WantStderr: []string{
+					fmt.Sprintf(`file %q is not writable so opening it read-only (sudo is needed to edit it); include "writable" flag to open it normally`, absPath(t, "alpha.txt")),
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf(`emacsclient -t -e '(progn (find-file "%s")(find-file-read-only-other-window "%s")(other-window 1))'`, absPath(t, "alpha.go"), absPath(t, "alpha.txt")),
+					},
+				},
+			},
+			want: &Emacs{
+				DaemonMode: true,
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go"), absPath(t, "alpha.txt")},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go"), absPath(t, "alpha.txt")}, Args: []string{absPath(t, "alpha.go"), absPath(t, "alpha.txt")}},
+				},
+			},
+		}, {
+			name: "writable flag opens unwritable files normally",
+			fileInfos: map[string]os.FileInfo{
+				absPath(t, "alpha.go"): fakeFileInfo{mode: 0444},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("alpha.go"), "-W"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg:            command.StringListValue(absPath(t, "alpha.go")),
+						writableFlag.Name(): command.BoolValue(true),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system %s", absPath(t, "alpha.go")),
+					},
+				},
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go"), "-W"},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go")}, Args: []string{absPath(t, "alpha.go"), "-W"}},
+				},
+			},
+		}, {
+			name: "readonly flag opens files read-only",
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("alpha.go"), path("alpha.txt"), "--readonly"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg:            command.StringListValue(absPath(t, "alpha.go"), absPath(t, "alpha.txt")),
+						readOnlyFlag.Name(): command.BoolValue(true),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf(`emacs --no-window-system --eval '(find-file-read-only "%s")' --eval '(find-file-read-only "%s")' %s`, absPath(t, "alpha.txt"), absPath(t, "alpha.go"), splitEval(absPath(t, "alpha.txt"))),
+					},
+				},
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go"), absPath(t, "alpha.txt"), "--readonly"},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go"), absPath(t, "alpha.txt")}, Args: []string{absPath(t, "alpha.go"), absPath(t, "alpha.txt"), "--readonly"}},
+				},
+			},
+		}, {
+			name: "short readonly flag opens files read-only in daemon mode",
+			e: &Emacs{
+				DaemonMode: true,
+			},
+			fileInfos: map[string]os.FileInfo{
+				absPath(t, "alpha.go"): fakeFileInfo{mode: 0444},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("alpha.go"), "-r"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg:            command.StringListValue(absPath(t, "alpha.go")),
+						readOnlyFlag.Name(): command.BoolValue(true),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf(`emacsclient -t -e '(progn (find-file-read-only "%s"))'`, absPath(t, "alpha.go")),
+					},
+				},
+			},
+			want: &Emacs{
+				DaemonMode: true,
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go"), "-r"},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go")}, Args: []string{absPath(t, "alpha.go"), "-r"}},
+				},
+			},
+		},
+		// Dry run
+		{
+			name: "dry run prints command",
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("alpha.go"), "12", "--dry-run"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg:          command.StringListValue(absPath(t, "alpha.go")),
+						lineArg:           command.IntListValue(12),
+						dryRunFlag.Name(): command.BoolValue(true),
+					},
+				},
+				WantStdout: []string{
+					fmt.Sprintf("emacs --no-window-system +12 %s", absPath(t, "alpha.go")),
+				},
+			},
+		}, {
+			name: "dry run prints daemon command",
+			e: &Emacs{
+				DaemonMode: true,
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("alpha.go"), "-y"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg:          command.StringListValue(absPath(t, "alpha.go")),
+						dryRunFlag.Name(): command.BoolValue(true),
+					},
+				},
+				WantStdout: []string{
+					fmt.Sprintf(`emacsclient -t -e '(progn (find-file "%s"))'`, absPath(t, "alpha.go")),
+				},
+			},
+		}, {
+			name: "dry run doesn't create directories for new files",
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("newDir", "newFile.txt"), "--new", "--dry-run"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg:          command.StringListValue(absPath(t, "newDir", "newFile.txt")),
+						newFileArg:        command.BoolValue(true),
+						dryRunFlag.Name(): command.BoolValue(true),
+					},
+				},
+				WantStdout: []string{
+					fmt.Sprintf("emacs --no-window-system %s", absPath(t, "newDir", "newFile.txt")),
+				},
+			},
+		}, {
+			name: "dry run prints cd command",
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("dirA"), "--dry-run"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg:          command.StringListValue(absPath(t, "dirA")),
+						dryRunFlag.Name(): command.BoolValue(true),
+					},
+				},
+				WantStdout: []string{
+					fmt.Sprintf("cd %s", absPath(t, "dirA")),
+				},
+			},
+		},
+		// Symbols
+		{
+			name: "searches for symbol",
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("alpha.go"), "@InitServer", path("alpha.txt")},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg:  command.StringListValue(absPath(t, "alpha.go"), absPath(t, "alpha.txt")),
+						symbolArg: command.StringListValue("InitServer"),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf(`emacs --no-window-system %s --eval '(progn (find-file "%s") (goto-char (point-min)) (re-search-forward "InitServer"))' %s`, absPath(t, "alpha.txt"), absPath(t, "alpha.go"), splitEval(absPath(t, "alpha.txt"))),
+					},
+				},
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go"), "@InitServer", absPath(t, "alpha.txt")},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go"), absPath(t, "alpha.txt")}, Args: []string{absPath(t, "alpha.go"), "@InitServer", absPath(t, "alpha.txt")}},
+				},
+			},
+		}, {
+			name: "searches for symbol in daemon mode",
+			e: &Emacs{
+				DaemonMode: true,
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("alpha.txt"), path("alpha.go"), "@InitServer"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg:  command.StringListValue(absPath(t, "alpha.txt"), absPath(t, "alpha.go")),
+						symbolArg: command.StringListValue("", "InitServer"),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf(`emacsclient -t -e '(progn (find-file "%s")(find-file-other-window "%s")(goto-char (point-min))(re-search-forward "InitServer")(other-window 1))'`, absPath(t, "alpha.txt"), absPath(t, "alpha.go")),
+					},
+				},
+			},
+			want: &Emacs{
+				DaemonMode: true,
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.txt"), absPath(t, "alpha.go"), "@InitServer"},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.txt"), absPath(t, "alpha.go")}, Args: []string{absPath(t, "alpha.txt"), absPath(t, "alpha.go"), "@InitServer"}},
+				},
+			},
+		},
+		// Glob patterns
+		{
+			name: "opens all files matching glob",
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("alpha.*")},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue(absPath(t, "alpha.*")),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system %s %s %s", absPath(t, "alpha.txt"), absPath(t, "alpha.go"), splitEval(absPath(t, "alpha.txt"))),
+					},
+				},
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.*")},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go"), absPath(t, "alpha.txt")}, Args: []string{absPath(t, "alpha.*")}},
+				},
+			},
+		}, {
+			name: "fails if glob matches nothing",
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("omega.*")},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue(absPath(t, "omega.*")),
+					},
+				},
+				WantStderr: []string{fmt.Sprintf("no files match glob pattern %q", absPath(t, "omega.*"))},
+				WantErr:    fmt.Errorf("no files match glob pattern %q", absPath(t, "omega.*")),
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "omega.*")},
+				},
+			},
+		},
+		// Sudo
+		{
+			name: "sudo opens unwritable files as root",
+			fileInfos: map[string]os.FileInfo{
+				absPath(t, "alpha.go"): fakeFileInfo{mode: 0444},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("alpha.go"), "12", "--sudo"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg:        command.StringListValue(absPath(t, "alpha.go")),
+						lineArg:         command.IntListValue(12),
+						sudoFlag.Name(): command.BoolValue(true),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system +12 /sudo::%s", absPath(t, "alpha.go")),
+					},
+				},
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go"), "12", "--sudo"},
+				},
+				History: []*historyEntry{
+					{Files: []string{"/sudo::" + absPath(t, "alpha.go")}, Args: []string{absPath(t, "alpha.go"), "12", "--sudo"}},
+				},
+			},
+		}, {
+			name: "sudo opens files as root in daemon mode",
+			e: &Emacs{
+				DaemonMode: true,
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("alpha.go"), "-S"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg:        command.StringListValue(absPath(t, "alpha.go")),
+						sudoFlag.Name(): command.BoolValue(true),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf(`emacsclient -t -e '(progn (find-file "/sudo::%s"))'`, absPath(t, "alpha.go")),
+					},
+				},
+			},
+			want: &Emacs{
+				DaemonMode: true,
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go"), "-S"},
+				},
+				History: []*historyEntry{
+					{Files: []string{"/sudo::" + absPath(t, "alpha.go")}, Args: []string{absPath(t, "alpha.go"), "-S"}},
+				},
+			},
+		},
+		// Remote files
+		{
+			name: "opens remote files without checking them",
+			etc: &command.ExecuteTestCase{
+				Args: []string{"/ssh:host:/etc/hosts", "3", path("alpha.go")},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue("/ssh:host:/etc/hosts", absPath(t, "alpha.go")),
+						lineArg:  command.IntListValue(3),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system %s +3 /ssh:host:/etc/hosts %s", absPath(t, "alpha.go"), splitEval(absPath(t, "alpha.go"))),
+					},
+				},
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {"/ssh:host:/etc/hosts", "3", absPath(t, "alpha.go")},
+				},
+				History: []*historyEntry{
+					{Files: []string{"/ssh:host:/etc/hosts", absPath(t, "alpha.go")}, Args: []string{"/ssh:host:/etc/hosts", "3", absPath(t, "alpha.go")}},
+				},
+			},
+		}, {
+			name: "opens multi-hop remote files in daemon mode",
+			e: &Emacs{
+				DaemonMode: true,
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"/ssh:user@host|sudo:host:/etc/hosts", "-r"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg:            command.StringListValue("/ssh:user@host|sudo:host:/etc/hosts"),
+						readOnlyFlag.Name(): command.BoolValue(true),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						`emacsclient -t -e '(progn (find-file-read-only "/ssh:user@host|sudo:host:/etc/hosts"))'`,
+					},
+				},
+			},
+			want: &Emacs{
+				DaemonMode: true,
+				Caches: map[string][]string{
+					cacheName: {"/ssh:user@host|sudo:host:/etc/hosts", "-r"},
+				},
+				History: []*historyEntry{
+					{Files: []string{"/ssh:user@host|sudo:host:/etc/hosts"}, Args: []string{"/ssh:user@host|sudo:host:/etc/hosts", "-r"}},
+				},
+			},
+		},
+		// With flag
+		{
+			name: "enables minor modes in basic mode",
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("alpha.go"), "--with", "flycheck-mode", path("alpha.txt"), "-w", "whitespace-mode"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue(absPath(t, "alpha.go"), absPath(t, "alpha.txt")),
+						withArg:  command.StringListValue("flycheck-mode", "whitespace-mode"),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system %s %s %s --eval '(dolist (b (buffer-list)) (with-current-buffer b (when buffer-file-name (flycheck-mode 1) (whitespace-mode 1))))'", absPath(t, "alpha.txt"), absPath(t, "alpha.go"), splitEval(absPath(t, "alpha.txt"))),
+					},
+				},
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go"), "--with", "flycheck-mode", absPath(t, "alpha.txt"), "-w", "whitespace-mode"},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go"), absPath(t, "alpha.txt")}, Args: []string{absPath(t, "alpha.go"), "--with", "flycheck-mode", absPath(t, "alpha.txt"), "-w", "whitespace-mode"}},
+				},
+			},
+		}, {
+			name: "enables minor modes in daemon mode",
+			e: &Emacs{
+				DaemonMode: true,
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("alpha.go"), "32", "--with", "flycheck-mode"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue(absPath(t, "alpha.go")),
+						lineArg:  command.IntListValue(32),
+						withArg:  command.StringListValue("flycheck-mode"),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf(`emacsclient -t -e '(progn (find-file "%s")(goto-line 32)(flycheck-mode 1))'`, absPath(t, "alpha.go")),
+					},
+				},
+			},
+			want: &Emacs{
+				DaemonMode: true,
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go"), "32", "--with", "flycheck-mode"},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go")}, Args: []string{absPath(t, "alpha.go"), "32", "--with", "flycheck-mode"}},
+				},
+			},
+		}, {
+			name: "fails on invalid minor mode",
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("alpha.go"), "--with", "(kill-emacs)"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue(absPath(t, "alpha.go")),
+						withArg:  command.StringListValue("(kill-emacs)"),
+					},
+				},
+				WantStderr: []string{`invalid minor mode "(kill-emacs)"`},
+				WantErr:    fmt.Errorf(`invalid minor mode "(kill-emacs)"`),
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go"), "--with", "(kill-emacs)"},
+				},
+			},
+		},
+		// FileHistory
+		{
+			name: "hist requires file",
+			etc: &command.ExecuteTestCase{
+				Args:       []string{"hist"},
+				WantStderr: []string{"not enough arguments"},
+				WantErr:    fmt.Errorf("not enough arguments"),
+			},
+		}, {
+			name: "hist prints nothing if no history",
+			etc: &command.ExecuteTestCase{
+				Args: []string{"hist", path("alpha.go")},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						histFileArg: command.StringValue(absPath(t, "alpha.go")),
+					},
+				},
+			},
+		}, {
+			name: "hist prints matching history newest first",
+			e: &Emacs{
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go")}},
+					{Files: []string{absPath(t, "alpha.txt")}},
+					{Files: []string{absPath(t, "alpha.txt"), absPath(t, "alpha.go")}},
+					{Files: []string{absPath(t, "other.txt")}},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"hist", path("alpha.go")},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						histFileArg: command.StringValue(absPath(t, "alpha.go")),
+					},
+				},
+				WantStdout: []string{
+					fmt.Sprintf("2: %s %s", absPath(t, "alpha.txt"), absPath(t, "alpha.go")),
+					fmt.Sprintf("0: %s", absPath(t, "alpha.go")),
+				},
+			},
+		}, {
+			name: "hist prints timestamps of matching history",
+			e: &Emacs{
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go")}},
+					{Files: []string{absPath(t, "alpha.go"), absPath(t, "alpha.txt")}, Time: time.Date(2021, time.May, 16, 13, 4, 5, 0, time.UTC)},
+					{Files: []string{absPath(t, "other.txt")}, Time: time.Date(2021, time.May, 17, 9, 0, 0, 0, time.UTC)},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"hist", path("alpha.go")},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						histFileArg: command.StringValue(absPath(t, "alpha.go")),
+					},
+				},
+				WantStdout: []string{
+					fmt.Sprintf("1: [2021-05-16 13:04:05] %s %s", absPath(t, "alpha.go"), absPath(t, "alpha.txt")),
+					fmt.Sprintf("0: %s", absPath(t, "alpha.go")),
+				},
+			},
+		},
+		// Recent
+		{
+			name: "recent lists distinct files",
+			e: &Emacs{
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go")}},
+					{Files: []string{absPath(t, "alpha.txt"), absPath(t, "other.txt")}},
+					{Files: []string{absPath(t, "alpha.go")}},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"r"},
+				WantStdout: []string{
+					fmt.Sprintf("1: %s", absPath(t, "alpha.go")),
+					fmt.Sprintf("2: %s", absPath(t, "alpha.txt")),
+					fmt.Sprintf("3: %s", absPath(t, "other.txt")),
+				},
+			},
+		}, {
+			name: "recent opens file at index",
+			e: &Emacs{
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go")}},
+					{Files: []string{absPath(t, "alpha.txt"), absPath(t, "other.txt")}},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"r", "2"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						recentArg: command.IntValue(2),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system %s", absPath(t, "other.txt")),
+					},
+				},
+			},
+			want: &Emacs{
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go")}},
+					{Files: []string{absPath(t, "alpha.txt"), absPath(t, "other.txt")}},
+					{Files: []string{absPath(t, "other.txt")}},
+				},
+			},
+		}, {
+			name: "recent fails if index is too large",
+			e: &Emacs{
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go")}},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"r", "2"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						recentArg: command.IntValue(2),
+					},
+				},
+				WantStderr: []string{"only 1 recent files exist"},
+				WantErr:    fmt.Errorf("only 1 recent files exist"),
+			},
+		}, {
+			name: "recent index must be positive",
+			etc: &command.ExecuteTestCase{
+				Args: []string{"r", "0"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						recentArg: command.IntValue(0),
+					},
+				},
+				WantStderr: []string{"validation failed: [IntPositive] value isn't positive"},
+				WantErr:    fmt.Errorf("validation failed: [IntPositive] value isn't positive"),
+			},
+		},
+		// Find
+		{
+			name: "find opens the most recent matching file",
+			e: &Emacs{
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go")}},
+					{Files: []string{absPath(t, "alpha.txt"), absPath(t, "other.txt")}},
+					{Files: []string{absPath(t, "compounds", "sodiumChloride")}},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"find", `alpha\.`, "-r"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						regexpArg:           command.StringValue(`alpha\.`),
+						emacsArg:            command.StringListValue(absPath(t, "alpha.txt")),
+						readOnlyFlag.Name(): command.BoolValue(true),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf(`emacs --no-window-system --eval '(find-file-read-only "%s")'`, absPath(t, "alpha.txt")),
+					},
+				},
+			},
+			want: &Emacs{
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go")}},
+					{Files: []string{absPath(t, "alpha.txt"), absPath(t, "other.txt")}},
+					{Files: []string{absPath(t, "compounds", "sodiumChloride")}},
+					{Files: []string{absPath(t, "alpha.txt")}, Args: []string{absPath(t, "alpha.txt"), "-r"}},
+				},
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.txt"), "-r"},
+				},
+			},
+		}, {
+			name: "find fails if no files match",
+			e: &Emacs{
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go")}},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"find", "beta"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						regexpArg: command.StringValue("beta"),
+					},
+				},
+				WantStderr: []string{`no recent files match "beta"`},
+				WantErr:    fmt.Errorf(`no recent files match "beta"`),
+			},
+		}, {
+			name: "find fails on invalid regexp",
+			etc: &command.ExecuteTestCase{
+				Args: []string{"find", "alpha["},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						regexpArg: command.StringValue("alpha["),
+					},
+				},
+				WantStderr: []string{"Invalid regexp: error parsing regexp: missing closing ]: `[`"},
+				WantErr:    fmt.Errorf("Invalid regexp: error parsing regexp: missing closing ]: `[`"),
+			},
+		},
+		// Init
+		{
+			name: "init opens the emacs init file",
+			etc: &command.ExecuteTestCase{
+				Args: []string{"init", "--new"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg:   command.StringListValue(absPath(t, ".emacs.d", "init.el")),
+						newFileArg: command.BoolValue(true),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system %s", absPath(t, ".emacs.d", "init.el")),
+					},
+				},
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {absPath(t, ".emacs.d", "init.el"), "--new"},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, ".emacs.d", "init.el")}, Args: []string{absPath(t, ".emacs.d", "init.el"), "--new"}},
+				},
+			},
+			wantMkdirs: []string{absPath(t, ".emacs.d")},
+		}, {
+			name: "scratch opens the scratch buffer",
+			etc: &command.ExecuteTestCase{
+				Args: []string{"scratch"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						noHistoryFlag.Name(): command.BoolValue(true),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						`emacs --no-window-system --eval '(switch-to-buffer "*scratch*")'`,
+					},
+				},
+			},
+		}, {
+			name: "scratch opens the scratch buffer in daemon mode",
+			e: &Emacs{
+				DaemonMode: true,
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"scratch"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						noHistoryFlag.Name(): command.BoolValue(true),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						`emacsclient -t -e '(progn (switch-to-buffer "*scratch*"))'`,
+					},
+				},
+			},
+		}, {
+			name: "init opens the file in EMACS_INIT in daemon mode",
+			e: &Emacs{
+				DaemonMode: true,
+			},
+			env: map[string]string{
+				"EMACS_INIT": absPath(t, "alpha.txt"),
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"init"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue(absPath(t, "alpha.txt")),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf(`emacsclient -t -e '(progn (find-file "%s"))'`, absPath(t, "alpha.txt")),
+					},
+				},
+			},
+			want: &Emacs{
+				DaemonMode: true,
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.txt")},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.txt")}, Args: []string{absPath(t, "alpha.txt")}},
+				},
+			},
+		}, {
+			name: "init fails if the init file does not exist",
+			etc: &command.ExecuteTestCase{
+				Args: []string{"init"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue(absPath(t, ".emacs.d", "init.el")),
+					},
+				},
+				WantStderr: []string{fmt.Sprintf(`file %q does not exist; include "new" flag to create it`, absPath(t, ".emacs.d", "init.el"))},
+				WantErr:    fmt.Errorf(`file %q does not exist; include "new" flag to create it`, absPath(t, ".emacs.d", "init.el")),
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {absPath(t, ".emacs.d", "init.el")},
+				},
+			},
+		},
+		// Last
+		{
+			name: "last opens the nth most recent file",
+			e: &Emacs{
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go")}},
+					{Files: []string{absPath(t, "alpha.txt"), absPath(t, "other.txt")}},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"last", "3"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						recentArg: command.IntValue(3),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system %s", absPath(t, "alpha.go")),
+					},
+				},
+			},
+			want: &Emacs{
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go")}},
+					{Files: []string{absPath(t, "alpha.txt"), absPath(t, "other.txt")}},
+					{Files: []string{absPath(t, "alpha.go")}},
+				},
+			},
+		}, {
+			name: "last requires an index",
+			etc: &command.ExecuteTestCase{
+				Args:       []string{"last"},
+				WantStderr: []string{"not enough arguments"},
+				WantErr:    fmt.Errorf("not enough arguments"),
+			},
+		}, {
+			name: "last fails if index is too large",
+			e: &Emacs{
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go")}},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"last", "2"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						recentArg: command.IntValue(2),
+					},
+				},
+				WantStderr: []string{"only 1 recent files exist"},
+				WantErr:    fmt.Errorf("only 1 recent files exist"),
+			},
+		},
+		// Relative lines
+		{
+			name: "shifts cached line numbers down",
+			e: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go"), "12", absPath(t, "alpha.txt") + ":3:4"},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"+10"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg:  command.StringListValue(absPath(t, "alpha.go"), absPath(t, "alpha.txt")),
+						lineArg:   command.IntListValue(22, 13),
+						columnArg: command.IntListValue(0, 4),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system +13:4 %s +22 %s %s", absPath(t, "alpha.txt"), absPath(t, "alpha.go"), splitEval(absPath(t, "alpha.txt"))),
+					},
+				},
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go"), "22", absPath(t, "alpha.txt") + ":13:4"},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go"), absPath(t, "alpha.txt")}, Args: []string{absPath(t, "alpha.go"), "22", absPath(t, "alpha.txt") + ":13:4"}},
+				},
+			},
+		}, {
+			name: "shifts cached line numbers before passthrough args",
+			e: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go"), "--", "--line-spacing", "2"},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"+10"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg:       command.StringListValue(absPath(t, "alpha.go")),
+						lineArg:        command.IntListValue(10),
+						passthroughArg: command.StringListValue("--line-spacing", "2"),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system +10 %s --line-spacing 2", absPath(t, "alpha.go")),
+					},
+				},
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go"), "10", "--", "--line-spacing", "2"},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go")}, Args: []string{absPath(t, "alpha.go"), "10", "--", "--line-spacing", "2"}},
+				},
+			},
+		}, {
+			name: "shifts cached line numbers up to the first line",
+			e: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go"), "12:3", "--monitor", "2"},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"-20"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg:           command.StringListValue(absPath(t, "alpha.go")),
+						lineArg:            command.IntListValue(1),
+						columnArg:          command.IntListValue(3),
+						monitorFlag.Name(): command.IntValue(2),
+					},
+				},
+				WantStderr: []string{`ignoring "monitor" flag since frames are only positioned in GUI mode`},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system +1:3 %s", absPath(t, "alpha.go")),
+					},
+				},
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go"), "1:3", "--monitor", "2"},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go")}, Args: []string{absPath(t, "alpha.go"), "1:3", "--monitor", "2"}},
+				},
+			},
+		}, {
+			name: "relative line is absolute if cache has no line numbers",
+			e: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go")},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"+10"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue(absPath(t, "alpha.go")),
+						lineArg:  command.IntListValue(10),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system +10 %s", absPath(t, "alpha.go")),
+					},
+				},
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go"), "10"},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go")}, Args: []string{absPath(t, "alpha.go"), "10"}},
+				},
+			},
+		},
+		// Historical
+		{
+			name: "h lists previous invocations",
+			e: &Emacs{
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go")}, Args: []string{absPath(t, "alpha.go"), "12", "-W"}},
+					{Files: []string{absPath(t, "other.txt")}},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"h"},
+				WantStdout: []string{
+					fmt.Sprintf("1: %s", absPath(t, "other.txt")),
+					fmt.Sprintf("0: %s 12 -W", absPath(t, "alpha.go")),
+				},
+			},
+		}, {
+			name: "h lists timestamps of previous invocations",
+			e: &Emacs{
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go")}},
+					{Files: []string{absPath(t, "other.txt")}, Time: time.Date(2021, time.May, 16, 13, 4, 5, 0, time.UTC)},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"h"},
+				WantStdout: []string{
+					fmt.Sprintf("1: [2021-05-16 13:04:05] %s", absPath(t, "other.txt")),
+					fmt.Sprintf("0: %s", absPath(t, "alpha.go")),
+				},
+			},
+		}, {
+			name: "opening files records the time in history",
+			now:  time.Date(2021, time.May, 16, 13, 4, 5, 0, time.UTC),
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("alpha.go")},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue(absPath(t, "alpha.go")),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system %s", absPath(t, "alpha.go")),
+					},
+				},
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go")},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go")}, Args: []string{absPath(t, "alpha.go")}, Time: time.Date(2021, time.May, 16, 13, 4, 5, 0, time.UTC)},
+				},
+			},
+		}, {
+			name: "h re-runs invocation at index",
+			e: &Emacs{
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go")}, Args: []string{absPath(t, "alpha.go"), "12"}},
+					{Files: []string{absPath(t, "other.txt")}},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"h", "0"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						historicalArg: command.IntValue(0),
+						emacsArg:      command.StringListValue(absPath(t, "alpha.go")),
+						lineArg:       command.IntListValue(12),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system +12 %s", absPath(t, "alpha.go")),
+					},
+				},
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go"), "12"},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go")}, Args: []string{absPath(t, "alpha.go"), "12"}},
+					{Files: []string{absPath(t, "other.txt")}},
+					{Files: []string{absPath(t, "alpha.go")}, Args: []string{absPath(t, "alpha.go"), "12"}},
+				},
+			},
+		}, {
+			name: "h re-runs files if no args were recorded",
+			e: &Emacs{
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go"), absPath(t, "other.txt")}},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"h", "0"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						historicalArg: command.IntValue(0),
+						emacsArg:      command.StringListValue(absPath(t, "alpha.go"), absPath(t, "other.txt")),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system %s %s %s", absPath(t, "other.txt"), absPath(t, "alpha.go"), splitEval(absPath(t, "other.txt"))),
+					},
+				},
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go"), absPath(t, "other.txt")},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go"), absPath(t, "other.txt")}},
+					{Files: []string{absPath(t, "alpha.go"), absPath(t, "other.txt")}, Args: []string{absPath(t, "alpha.go"), absPath(t, "other.txt")}},
+				},
+			},
+		}, {
+			name: "h fails if index is too large",
+			e: &Emacs{
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go")}},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"h", "1"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						historicalArg: command.IntValue(1),
+					},
+				},
+				WantStderr: []string{"only 1 history entries exist"},
+				WantErr:    fmt.Errorf("only 1 history entries exist"),
+			},
+		}, {
+			name: "ext sets extension line",
+			etc: &command.ExecuteTestCase{
+				Args: []string{"settings", "ext", "log", "end"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						extensionArg: command.StringValue("log"),
+						extLineArg:   command.StringValue("end"),
+					},
+				},
+				WantStdout: []string{"Default line for .log files set to end."},
+			},
+			want: &Emacs{
+				ExtensionLines: map[string]string{".log": "end"},
+			},
+		}, {
+			name: "ext sets extension line number",
+			e: &Emacs{
+				ExtensionLines: map[string]string{".log": "end"},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"settings", "ext", ".md", "3"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						extensionArg: command.StringValue(".md"),
+						extLineArg:   command.StringValue("3"),
+					},
+				},
+				WantStdout: []string{"Default line for .md files set to 3."},
+			},
+			want: &Emacs{
+				ExtensionLines: map[string]string{".log": "end", ".md": "3"},
+			},
+		}, {
+			name: "ext fails on invalid line",
+			etc: &command.ExecuteTestCase{
+				Args: []string{"settings", "ext", ".log", "start"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						extensionArg: command.StringValue(".log"),
+						extLineArg:   command.StringValue("start"),
+					},
+				},
+				WantStderr: []string{`validation failed: value must be "end" or a positive line number`},
+				WantErr:    fmt.Errorf(`validation failed: value must be "end" or a positive line number`),
+			},
+		}, {
+			name: "ext unsets extension line",
+			e: &Emacs{
+				ExtensionLines: map[string]string{".log": "end", ".md": "3"},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"settings", "ext", ".log"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						extensionArg: command.StringValue(".log"),
+					},
+				},
+				WantStdout: []string{"Default line for .log files unset."},
+			},
+			want: &Emacs{
+				ExtensionLines: map[string]string{".md": "3"},
+			},
+		}, {
+			name: "ext lists extension lines",
+			e: &Emacs{
+				ExtensionLines: map[string]string{".md": "3", ".log": "end"},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"settings", "ext"},
+				WantStdout: []string{
+					".log: end",
+					".md: 3",
+				},
+			},
+		}, {
+			name: "opens file at extension line",
+			e: &Emacs{
+				ExtensionLines: map[string]string{".txt": "end", ".go": "7"},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{path("alpha.txt"), path("alpha.go"), "12"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						emacsArg: command.StringListValue(absPath(t, "alpha.txt"), absPath(t, "alpha.go")),
+						lineArg:  command.IntListValue(0, 12),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf(`emacs --no-window-system +12 %s --eval '(progn (find-file "%s") (goto-char (point-max)))' %s`, absPath(t, "alpha.go"), absPath(t, "alpha.txt"), splitEval(absPath(t, "alpha.go"))),
+					},
+				},
+			},
+			want: &Emacs{
+				ExtensionLines: map[string]string{".txt": "end", ".go": "7"},
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.txt"), absPath(t, "alpha.go"), "12"},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.txt"), absPath(t, "alpha.go")}, Args: []string{absPath(t, "alpha.txt"), absPath(t, "alpha.go"), "12"}},
+				},
+			},
+		}, {
+			name: "open-group opens all aliases in the group",
+			e: &Emacs{
+				DaemonMode: true,
+				Aliases: map[string]map[string][]string{
+					"docs": {
+						"uno":  {absPath(t, "alpha.go")},
+						"duo":  {absPath(t, "alpha.txt"), "3", absPath(t, "alpha.go")},
+						"tres": {absPath(t, "other.txt"), "$"},
+					},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"open-group", "docs"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						openGroupArg: command.StringValue("docs"),
+						emacsArg:     command.StringListValue(absPath(t, "alpha.txt"), absPath(t, "alpha.go"), absPath(t, "other.txt"), absPath(t, "alpha.go")),
+						lineArg:      command.IntListValue(3),
+						endArg:       command.IntListValue(0, 0, 1),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf(`emacsclient -t -e '(progn (find-file "%s")(goto-line 3)(find-file-other-window "%s")(find-file-other-window "%s")(goto-char (point-max)))'`, absPath(t, "alpha.txt"), absPath(t, "alpha.go"), absPath(t, "other.txt")),
+					},
+				},
+			},
+			want: &Emacs{
+				DaemonMode: true,
+				Aliases: map[string]map[string][]string{
+					"docs": {
+						"uno":  {absPath(t, "alpha.go")},
+						"duo":  {absPath(t, "alpha.txt"), "3", absPath(t, "alpha.go")},
+						"tres": {absPath(t, "other.txt"), "$"},
+					},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.txt"), absPath(t, "alpha.go"), absPath(t, "other.txt")}},
+				},
+			},
+		}, {
+			name: "open-group expands aliases referenced by the group",
+			e: &Emacs{
+				DaemonMode: true,
+				Aliases: map[string]map[string][]string{
+					"docs": {
+						"salt":  {absPath(t, "alpha.go")},
+						"combo": {"salt", absPath(t, "alpha.txt"), "%50"},
+					},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"open-group", "docs"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						openGroupArg: command.StringValue("docs"),
+						emacsArg:     command.StringListValue(absPath(t, "alpha.go"), absPath(t, "alpha.txt"), absPath(t, "alpha.go")),
+						percentArg:   command.StringListValue("", "50"),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf(`emacsclient -t -e '(progn (find-file "%s")(find-file-other-window "%s")(goto-char (/ (* 50 (point-max)) 100))(other-window 1))'`, absPath(t, "alpha.go"), absPath(t, "alpha.txt")),
+					},
+				},
+			},
+			want: &Emacs{
+				DaemonMode: true,
+				Aliases: map[string]map[string][]string{
+					"docs": {
+						"salt":  {absPath(t, "alpha.go")},
+						"combo": {"salt", absPath(t, "alpha.txt"), "%50"},
+					},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go"), absPath(t, "alpha.txt")}},
+				},
+			},
+		}, {
+			name: "open-group fails if a file doesn't exist",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					"docs": {
+						"uno": {absPath(t, "alpha.go")},
+						"duo": {absPath(t, "missing.txt")},
+					},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"open-group", "docs"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						openGroupArg: command.StringValue("docs"),
+						emacsArg:     command.StringListValue(absPath(t, "missing.txt"), absPath(t, "alpha.go")),
+					},
+				},
+				WantStderr: []string{fmt.Sprintf(`file %q does not exist; include "new" flag to create it`, absPath(t, "missing.txt"))},
+				WantErr:    fmt.Errorf(`file %q does not exist; include "new" flag to create it`, absPath(t, "missing.txt")),
+			},
+		}, {
+			name: "open-group fails if the group doesn't exist",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"uno": {absPath(t, "alpha.go")},
+					},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"open-group", "docs"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						openGroupArg: command.StringValue("docs"),
+					},
+				},
+				WantStderr: []string{`alias group "docs" does not exist`},
+				WantErr:    fmt.Errorf(`alias group "docs" does not exist`),
+			},
+		}, {
+			name: "repeat re-runs invocation relative to the most recent",
+			e: &Emacs{
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go")}, Args: []string{absPath(t, "alpha.go"), "12"}},
+					{Files: []string{absPath(t, "other.txt")}},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"repeat", "2"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						repeatArg: command.IntValue(2),
+						emacsArg:  command.StringListValue(absPath(t, "alpha.go")),
+						lineArg:   command.IntListValue(12),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system +12 %s", absPath(t, "alpha.go")),
+					},
+				},
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go"), "12"},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go")}, Args: []string{absPath(t, "alpha.go"), "12"}},
+					{Files: []string{absPath(t, "other.txt")}},
+					{Files: []string{absPath(t, "alpha.go")}, Args: []string{absPath(t, "alpha.go"), "12"}},
+				},
+			},
+		}, {
+			name: "repeat 1 re-runs the most recent invocation",
+			e: &Emacs{
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "other.txt")}},
+					{Files: []string{absPath(t, "alpha.go")}},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"repeat", "1"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						repeatArg: command.IntValue(1),
+						emacsArg:  command.StringListValue(absPath(t, "alpha.go")),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system %s", absPath(t, "alpha.go")),
+					},
+				},
+			},
+			want: &Emacs{
+				Caches: map[string][]string{
+					cacheName: {absPath(t, "alpha.go")},
+				},
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "other.txt")}},
+					{Files: []string{absPath(t, "alpha.go")}},
+					{Files: []string{absPath(t, "alpha.go")}, Args: []string{absPath(t, "alpha.go")}},
+				},
+			},
+		}, {
+			name: "repeat fails if offset is too large",
+			e: &Emacs{
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go")}},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"repeat", "2"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						repeatArg: command.IntValue(2),
+					},
+				},
+				WantStderr: []string{"only 1 history entries exist"},
+				WantErr:    fmt.Errorf("only 1 history entries exist"),
+			},
+		}, {
+			name: "repeat requires a positive offset",
+			etc: &command.ExecuteTestCase{
+				Args: []string{"repeat", "0"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						repeatArg: command.IntValue(0),
+					},
+				},
+				WantStderr: []string{"validation failed: [IntPositive] value isn't positive"},
+				WantErr:    fmt.Errorf("validation failed: [IntPositive] value isn't positive"),
+			},
+		},
+		// Export and import
+		{
+			name: "export outputs aliases as json",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"salt": {"/compounds/sodiumChloride"},
+						"city": {"/catan/oreAndWheat", "42"},
+					},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"export"},
+				WantStdout: []string{strings.Join([]string{
+					"{",
+					`  "fileAliases": {`,
+					`    "city": [`,
+					`      "/catan/oreAndWheat",`,
+					`      "42"`,
+					"    ],",
+					`    "salt": [`,
+					`      "/compounds/sodiumChloride"`,
+					"    ]",
+					"  }",
+					"}",
+				}, "\n")},
+			},
+		}, {
+			name: "import merges aliases",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"salt": {"/compounds/sodiumChloride"},
+					},
+				},
+			},
+			stdin: `{"fileAliases": {"salt": ["/compounds/sodiumChloride"], "city": ["/catan/oreAndWheat", "42"]}, "docs": {"readme": ["/README.md"]}}`,
+			etc: &command.ExecuteTestCase{
+				Args: []string{"import"},
+				WantStdout: []string{
+					"Added alias readme: /README.md",
+					"Added alias city: /catan/oreAndWheat 42",
+				},
+			},
+			want: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"salt": {"/compounds/sodiumChloride"},
+						"city": {"/catan/oreAndWheat", "42"},
+					},
+					"docs": {
+						"readme": {"/README.md"},
+					},
+				},
+			},
+		}, {
+			name: "import fails on conflicting alias",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"salt": {"/compounds/sodiumChloride"},
+					},
+				},
+			},
+			stdin: `{"fileAliases": {"city": ["/catan/oreAndWheat"], "salt": ["/NaCl"]}}`,
+			etc: &command.ExecuteTestCase{
+				Args:       []string{"import"},
+				WantStderr: []string{`alias "salt" already exists in group "fileAliases"; include "force" flag to overwrite it`},
+				WantErr:    fmt.Errorf(`alias "salt" already exists in group "fileAliases"; include "force" flag to overwrite it`),
+			},
+		}, {
+			name: "import overwrites conflicting alias with force flag",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"salt": {"/compounds/sodiumChloride"},
+					},
+				},
+			},
+			stdin: `{"fileAliases": {"salt": ["/NaCl"]}}`,
+			etc: &command.ExecuteTestCase{
+				Args: []string{"import", "-f"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						forceFlag.Name(): command.BoolValue(true),
+					},
+				},
+				WantStdout: []string{"Updated alias salt: /NaCl"},
+			},
+			want: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"salt": {"/NaCl"},
+					},
+				},
+			},
+		}, {
+			name: "quiet flag suppresses import summary",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"salt": {"/compounds/sodiumChloride"},
+					},
+				},
+			},
+			stdin: `{"fileAliases": {"city": ["/catan/oreAndWheat"]}}`,
+			etc: &command.ExecuteTestCase{
+				Args: []string{"import", "-q"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						quietFlag.Name(): command.BoolValue(true),
+					},
+				},
+			},
+			want: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"salt": {"/compounds/sodiumChloride"},
+						"city": {"/catan/oreAndWheat"},
+					},
+				},
+			},
+		}, {
+			name:  "import fails on relative path",
+			stdin: `{"fileAliases": {"salt": ["compounds/sodiumChloride"]}}`,
+			etc: &command.ExecuteTestCase{
+				Args:       []string{"import"},
+				WantStderr: []string{`alias "salt" references non-absolute path "compounds/sodiumChloride"`},
+				WantErr:    fmt.Errorf(`alias "salt" references non-absolute path "compounds/sodiumChloride"`),
+			},
+		}, {
+			name:  "import fails on invalid json",
+			stdin: `{`,
+			etc: &command.ExecuteTestCase{
+				Args:       []string{"import"},
+				WantStderr: []string{"failed to unmarshal aliases: unexpected end of JSON input"},
+				WantErr:    fmt.Errorf("failed to unmarshal aliases: unexpected end of JSON input"),
+			},
+		},
+		// PruneAliases
+		{
+			name: "prune removes aliases with missing files",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"salt":   {absPath(t, "compounds", "potassiumChloride"), "12"},
+						"uno":    {absPath(t, "alpha.go")},
+						"duo":    {absPath(t, "alpha.txt"), absPath(t, "beta.txt")},
+						"nada":   {absPath(t, "beta.txt"), absPath(t, "gamma.txt")},
+						"logs":   {"!journalctl -u myservice"},
+						"remote": {"/ssh:host:/etc/hosts"},
+					},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"prune"},
+				WantStdout: []string{
+					fmt.Sprintf("Deleted alias nada: %s %s", absPath(t, "beta.txt"), absPath(t, "gamma.txt")),
+					fmt.Sprintf("Deleted alias salt: %s 12", absPath(t, "compounds", "potassiumChloride")),
+				},
+			},
+			want: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"uno":    {absPath(t, "alpha.go")},
+						"duo":    {absPath(t, "alpha.txt"), absPath(t, "beta.txt")},
+						"logs":   {"!journalctl -u myservice"},
+						"remote": {"/ssh:host:/etc/hosts"},
+					},
+				},
+			},
+		}, {
+			name: "quiet flag suppresses prune summary",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"uno":  {absPath(t, "alpha.go")},
+						"nada": {absPath(t, "beta.txt")},
+					},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"prune", "-q"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						quietFlag.Name(): command.BoolValue(true),
+					},
+				},
+			},
+			want: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"uno": {absPath(t, "alpha.go")},
+					},
+				},
+			},
+		}, {
+			name: "prune removes aliases with files removed from the filesystem",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"uno": {absPath(t, "alpha.go")},
+						"duo": {absPath(t, "alpha.txt")},
+					},
+				},
+			},
+			fileInfos: map[string]os.FileInfo{
+				absPath(t, "alpha.go"): nil,
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"prune"},
+				WantStdout: []string{
+					fmt.Sprintf("Deleted alias uno: %s", absPath(t, "alpha.go")),
+				},
+			},
+			want: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"duo": {absPath(t, "alpha.txt")},
+					},
+				},
+			},
+		}, {
+			name: "prune clears new file flags and use counts of removed aliases",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"uno": {absPath(t, "alpha.go")},
+						"duo": {absPath(t, "alpha.txt")},
+					},
+				},
+				NewFileAliases: map[string]map[string]bool{
+					fileAliaserName: {
+						"uno": true,
+						"duo": true,
+					},
+				},
+				AliasUses: map[string]map[string]int{
+					fileAliaserName: {
+						"uno": 4,
+						"duo": 1,
+					},
+				},
+			},
+			fileInfos: map[string]os.FileInfo{
+				absPath(t, "alpha.go"): nil,
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"prune"},
+				WantStdout: []string{
+					fmt.Sprintf("Deleted alias uno: %s", absPath(t, "alpha.go")),
+				},
+			},
+			want: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"duo": {absPath(t, "alpha.txt")},
+					},
+				},
+				NewFileAliases: map[string]map[string]bool{
+					fileAliaserName: {
+						"duo": true,
+					},
+				},
+				AliasUses: map[string]map[string]int{
+					fileAliaserName: {
+						"duo": 1,
+					},
+				},
+			},
+		}, {
+			name: "prune dry run doesn't remove aliases",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"uno":  {absPath(t, "alpha.go")},
+						"nada": {absPath(t, "beta.txt")},
+					},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"prune", "--dry-run"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						dryRunFlag.Name(): command.BoolValue(true),
+					},
+				},
+				WantStdout: []string{
+					fmt.Sprintf("Would remove alias nada: %s", absPath(t, "beta.txt")),
+				},
+			},
+		}, {
+			name: "prune removes unused aliases",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"uno":  {absPath(t, "alpha.go")},
+						"duo":  {absPath(t, "alpha.txt")},
+						"nada": {absPath(t, "beta.txt")},
+					},
+				},
+				AliasUses: map[string]map[string]int{
+					fileAliaserName: {"uno": 2},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"prune", "--unused"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						unusedFlag.Name(): command.BoolValue(true),
+					},
+				},
+				WantStdout: []string{
+					fmt.Sprintf("Deleted alias duo: %s", absPath(t, "alpha.txt")),
+					fmt.Sprintf("Deleted alias nada: %s", absPath(t, "beta.txt")),
+				},
+			},
+			want: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"uno": {absPath(t, "alpha.go")},
+					},
+				},
+				AliasUses: map[string]map[string]int{
+					fileAliaserName: {"uno": 2},
+				},
+			},
+		}, {
+			name: "prune dry run doesn't remove unused aliases",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"uno": {absPath(t, "alpha.go")},
+					},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"prune", "-U", "-y"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						unusedFlag.Name(): command.BoolValue(true),
+						dryRunFlag.Name(): command.BoolValue(true),
+					},
+				},
+				WantStdout: []string{
+					fmt.Sprintf("Would remove alias uno: %s", absPath(t, "alpha.go")),
+				},
+			},
+		},
+		// RenameAlias
+		{
+			name: "rn keeps alias uses",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"slat": {path("compounds", "sodiumChloride")},
+					},
+				},
+				AliasUses: map[string]map[string]int{
+					fileAliaserName: {"slat": 2},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"rn", "slat", "salt"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						aliasArg:    command.StringValue("slat"),
+						newAliasArg: command.StringValue("salt"),
+					},
+				},
+				WantStdout: []string{"Renamed alias slat to salt"},
+			},
+			want: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"salt": {path("compounds", "sodiumChloride")},
+					},
+				},
+				AliasUses: map[string]map[string]int{
+					fileAliaserName: {"salt": 2},
+				},
+			},
+		}, {
+			name: "rn moves new file flag",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"slat": {path("compounds", "sodiumChloride")},
+					},
+				},
+				NewFileAliases: map[string]map[string]bool{
+					fileAliaserName: {"slat": true},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"rn", "slat", "salt"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						aliasArg:    command.StringValue("slat"),
+						newAliasArg: command.StringValue("salt"),
+					},
+				},
+				WantStdout: []string{"Renamed alias slat to salt"},
+			},
+			want: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"salt": {path("compounds", "sodiumChloride")},
+					},
+				},
+				NewFileAliases: map[string]map[string]bool{
+					fileAliaserName: {"salt": true},
+				},
+			},
+		}, {
+			name: "rn renames alias",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"slat": {path("compounds", "sodiumChloride")},
+						"city": {path("catan", "oreAndWheat")},
+					},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"rn", "slat", "salt"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						aliasArg:    command.StringValue("slat"),
+						newAliasArg: command.StringValue("salt"),
+					},
+				},
+				WantStdout: []string{"Renamed alias slat to salt"},
+			},
+			want: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"salt": {path("compounds", "sodiumChloride")},
+						"city": {path("catan", "oreAndWheat")},
+					},
+				},
+			},
+		}, {
+			name: "which prints alias files",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"salt": {absPath(t, "compounds", "sodiumChloride"), "12", absPath(t, "alpha.go"), "3:4"},
+						"city": {absPath(t, "catan", "oreAndWheat")},
+					},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"which", "salt"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						aliasArg: command.StringValue("salt"),
+					},
+				},
+				WantStdout: []string{
+					absPath(t, "compounds", "sodiumChloride"),
+					absPath(t, "alpha.go"),
+				},
+			},
+		}, {
+			name: "which ignores percent positions",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"half": {absPath(t, "alpha.txt"), "%50", absPath(t, "alpha.go")},
+					},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"which", "half"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						aliasArg: command.StringValue("half"),
+					},
+				},
+				WantStdout: []string{
+					absPath(t, "alpha.txt"),
+					absPath(t, "alpha.go"),
+				},
+			},
+		}, {
+			name: "which prints alias files with percent signs",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"pct": {absPath(t, "100%done.txt")},
+					},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"which", "pct"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						aliasArg: command.StringValue("pct"),
+					},
+				},
+				WantStdout: []string{
+					absPath(t, "100%done.txt"),
+				},
+			},
+		}, {
+			name: "which fails if alias does not exist",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"city": {absPath(t, "catan", "oreAndWheat")},
+					},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"which", "salt"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						aliasArg: command.StringValue("salt"),
+					},
+				},
+				WantStderr: []string{`Alias "salt" does not exist`},
+				WantErr:    fmt.Errorf(`Alias "salt" does not exist`),
+			},
+		}, {
+			name: "rn fails if alias does not exist",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"city": {path("catan", "oreAndWheat")},
+					},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"rn", "slat", "salt"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						aliasArg:    command.StringValue("slat"),
+						newAliasArg: command.StringValue("salt"),
+					},
+				},
+				WantStderr: []string{`Alias "slat" does not exist`},
+				WantErr:    fmt.Errorf(`Alias "slat" does not exist`),
+			},
+		}, {
+			name: "rn fails if new alias already exists",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"slat": {path("compounds", "sodiumChloride")},
+						"city": {path("catan", "oreAndWheat")},
+					},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"rn", "slat", "city"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						aliasArg:    command.StringValue("slat"),
+						newAliasArg: command.StringValue("city"),
+					},
+				},
+				WantStderr: []string{`Alias "city" already exists`},
+				WantErr:    fmt.Errorf(`Alias "city" already exists`),
+			},
+		},
+		// AliasDiff
+		{
+			name: "adiff requires two aliases",
+			etc: &command.ExecuteTestCase{
+				Args: []string{"adiff", "salt"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						aliasArg: command.StringListValue("salt"),
+					},
+				},
+				WantStderr: []string{"not enough arguments"},
+				WantErr:    fmt.Errorf("not enough arguments"),
+			},
+		}, {
+			name: "adiff fails if alias does not exist",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"salt": {"compounds/sodiumChloride"},
+					},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"adiff", "salt", "city"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						aliasArg: command.StringListValue("salt", "city"),
+					},
+				},
+				WantStderr: []string{`Alias "city" does not exist`},
+				WantErr:    fmt.Errorf(`Alias "city" does not exist`),
+			},
+		}, {
+			name: "adiff prints differences",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"salt": {"compounds/sodiumChloride", "shared/b", "shared/a"},
+						"city": {"shared/a", "catan/oreAndWheat", "catan/brick", "shared/b"},
+					},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"adiff", "salt", "city"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						aliasArg: command.StringListValue("salt", "city"),
+					},
+				},
+				WantStdout: []string{
+					"Only in salt: compounds/sodiumChloride",
+					"Only in city: catan/brick catan/oreAndWheat",
+					"Shared: shared/a shared/b",
+				},
+			},
+		}, {
+			name: "adiff includes line numbers with files",
+			e: &Emacs{
+				Aliases: map[string]map[string][]string{
+					fileAliaserName: {
+						"salt": {"compounds/sodiumChloride", "42", "shared/a"},
+						"city": {"shared/a", "compounds/sodiumChloride"},
+					},
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"adiff", "salt", "city"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						aliasArg: command.StringListValue("salt", "city"),
+					},
+				},
+				WantStdout: []string{
+					"Only in salt: compounds/sodiumChloride:42",
+					"Only in city: compounds/sodiumChloride",
+					"Shared: shared/a",
+				},
+			},
+		},
+		// OpenDir
+		{
+			name: "opendir opens all files in directory",
+			etc: &command.ExecuteTestCase{
+				Args: []string{"opendir", path("nested")},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						dirArg: command.StringValue(absPath(t, "nested")),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system %s %s %s", absPath(t, "nested", "top.txt"), absPath(t, "nested", "sub", "middle.txt"), absPath(t, "nested", "sub", "deeper", "bottom.txt")),
+					},
+				},
+			},
+			want: &Emacs{
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "nested", "sub", "deeper", "bottom.txt"), absPath(t, "nested", "sub", "middle.txt"), absPath(t, "nested", "top.txt")}},
+				},
+			},
+		}, {
+			name: "opendir with zero depth only opens top level files",
+			etc: &command.ExecuteTestCase{
+				Args: []string{"opendir", "--depth", "0", path("nested")},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						dirArg:           command.StringValue(absPath(t, "nested")),
+						depthFlag.Name(): command.IntValue(0),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system %s", absPath(t, "nested", "top.txt")),
+					},
+				},
+			},
+			want: &Emacs{
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "nested", "top.txt")}},
+				},
+			},
+		}, {
+			name: "opendir respects depth",
+			etc: &command.ExecuteTestCase{
+				Args: []string{"opendir", path("nested"), "-L", "1"},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						dirArg:           command.StringValue(absPath(t, "nested")),
+						depthFlag.Name(): command.IntValue(1),
+					},
+				},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system %s %s %s", absPath(t, "nested", "top.txt"), absPath(t, "nested", "sub", "middle.txt"), splitEval(absPath(t, "nested", "top.txt"))),
+					},
+				},
+			},
+			want: &Emacs{
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "nested", "sub", "middle.txt"), absPath(t, "nested", "top.txt")}},
+				},
+			},
+		}, {
+			name: "opendir fails for file",
+			etc: &command.ExecuteTestCase{
+				Args: []string{"opendir", path("alpha.go")},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						dirArg: command.StringValue(absPath(t, "alpha.go")),
+					},
+				},
+				WantStderr: []string{fmt.Sprintf("%q is not a directory", absPath(t, "alpha.go"))},
+				WantErr:    fmt.Errorf("%q is not a directory", absPath(t, "alpha.go")),
+			},
+		},
+		// FromGrep
+		{
+			name: "fromgrep opens files at their lines",
+			stdin: strings.Join([]string{
+				fmt.Sprintf("%s:12:func main() {", path("alpha.go")),
+				"",
+				fmt.Sprintf("%s:3:hello", path("alpha.txt")),
+			}, "\n"),
+			etc: &command.ExecuteTestCase{
+				Args: []string{"fromgrep"},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system +3 %s +12 %s %s", absPath(t, "alpha.txt"), absPath(t, "alpha.go"), splitEval(absPath(t, "alpha.txt"))),
+					},
+				},
+			},
+			want: &Emacs{
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go"), absPath(t, "alpha.txt")}},
+				},
+			},
+		}, {
+			name: "fromgrep only uses first line per file",
+			e: &Emacs{
+				DaemonMode: true,
+			},
+			stdin: strings.Join([]string{
+				fmt.Sprintf("%s:12:func main() {", path("alpha.go")),
+				fmt.Sprintf("%s:14:	fmt.Println(\"hello\")", path("alpha.go")),
+			}, "\n"),
+			etc: &command.ExecuteTestCase{
+				Args: []string{"fromgrep"},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf(`emacsclient -t -e '(progn (find-file "%s")(goto-line 12))'`, absPath(t, "alpha.go")),
+					},
+				},
+			},
+			want: &Emacs{
+				DaemonMode: true,
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go")}},
+				},
+			},
+		}, {
+			name:  "fromgrep fails on invalid line number",
+			stdin: "alpha.go:twelve:func main() {",
+			etc: &command.ExecuteTestCase{
+				Args:       []string{"fromgrep"},
+				WantStderr: []string{`invalid line number in grep line "alpha.go:twelve:func main() {"`},
+				WantErr:    fmt.Errorf(`invalid line number in grep line "alpha.go:twelve:func main() {"`),
+			},
+		}, {
+			name: "fromgrep fails if no files",
+			etc: &command.ExecuteTestCase{
+				Args:       []string{"fromgrep"},
+				WantStderr: []string{"no files found in grep output"},
+				WantErr:    fmt.Errorf("no files found in grep output"),
+			},
+		}, {
+			name: "git opens modified and untracked files",
+			runs: map[string][]string{
+				"git rev-parse --show-toplevel": {absPath(t), ""},
+				"git -C": {
+					" M alpha.go",
+					"?? other.txt",
+					" D deleted.txt",
+					"?? compounds/",
+					"R  old.txt -> alpha.txt",
+					"",
+				},
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"git"},
+				WantExecuteData: &command.ExecuteData{
+					Executable: []string{
+						fmt.Sprintf("emacs --no-window-system %s %s %s", absPath(t, "alpha.txt"), absPath(t, "other.txt"), absPath(t, "alpha.go")),
+					},
+				},
+			},
+			want: &Emacs{
+				History: []*historyEntry{
+					{Files: []string{absPath(t, "alpha.go"), absPath(t, "other.txt"), absPath(t, "alpha.txt")}},
+				},
+			},
+		}, {
+			name: "git fails outside of a git repository",
+			etc: &command.ExecuteTestCase{
+				Args:       []string{"git"},
+				WantStderr: []string{"not in a git repository"},
+				WantErr:    fmt.Errorf("not in a git repository"),
+			},
+		}, {
+			name: "git fails if there are no changed files",
+			runs: map[string][]string{
+				"git rev-parse --show-toplevel": {absPath(t), ""},
+				"git -C":                        {""},
+			},
+			etc: &command.ExecuteTestCase{
+				Args:       []string{"git"},
+				WantStderr: []string{"no modified or untracked files"},
+				WantErr:    fmt.Errorf("no modified or untracked files"),
+			},
+		}, {
+			name: "fromgrep fails if grep file does not exist",
+			etc: &command.ExecuteTestCase{
+				Args: []string{"fromgrep", path("grep.txt")},
+				WantData: &command.Data{
+					Values: map[string]*command.Value{
+						grepFileArg: command.StringValue(absPath(t, "grep.txt")),
+					},
+				},
+				WantStderr: []string{fmt.Sprintf("failed to open grep file: open %s: no such file or directory", absPath(t, "grep.txt"))},
+				WantErr:    fmt.Errorf("failed to open grep file: open %s: no such file or directory", absPath(t, "grep.txt")),
 			},
 		},
 		/* Useful for commenting out tests. */
@@ -1000,21 +8028,587 @@ func TestEmacsExecution(t *testing.T) {
 			if test.e == nil {
 				test.e = &Emacs{}
 			}
+			oldStdin := stdin
+			stdin = strings.NewReader(test.stdin)
+			defer func() { stdin = oldStdin }()
+			ffs := &fakeFileSystem{
+				fileInfos: test.fileInfos,
+				globs:     test.globs,
+			}
+			test.e.fs = ffs
+			oldGetenv := getenv
+			getenv = func(key string) string { return test.env[key] }
+			defer func() { getenv = oldGetenv }()
+			oldUserHomeDir := userHomeDir
+			userHomeDir = func() (string, error) { return absPath(t), nil }
+			defer func() { userHomeDir = oldUserHomeDir }()
+			oldNow := now
+			now = func() time.Time { return test.now }
+			defer func() { now = oldNow }()
+			oldGetwd := getwd
+			getwd = func() (string, error) { return absPath(t), nil }
+			defer func() { getwd = oldGetwd }()
+			oldRun := run
+			run = func(contents []string) ([]string, error) {
+				c := strings.Join(contents, "\n")
+				for k, v := range test.runs {
+					if strings.HasPrefix(c, k) {
+						return v, nil
+					}
+				}
+				return nil, fmt.Errorf("failed to run command: exit status 1")
+			}
+			defer func() { run = oldRun }()
+			fc := &fakeClipboard{}
+			oldClip := clip
+			clip = fc
+			defer func() { clip = oldClip }()
 			test.etc.Node = test.e.Node()
 			command.ExecuteTest(t, test.etc, nil)
 			command.ChangeTest(t, test.want, test.e, cmpopts.IgnoreUnexported(Emacs{}), cmpopts.EquateEmpty())
+			if diff := cmp.Diff(test.wantClipboard, fc.copied, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("execute(%v) copied wrong values to clipboard (-want, +got):\n%s", test.etc.Args, diff)
+			}
+			if diff := cmp.Diff(test.wantMkdirs, ffs.mkdirs, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("execute(%v) created wrong directories (-want, +got):\n%s", test.etc.Args, diff)
+			}
+			if diff := cmp.Diff(test.wantCreates, ffs.creates, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("execute(%v) created wrong files (-want, +got):\n%s", test.etc.Args, diff)
+			}
+		})
+	}
+}
+
+func TestEditorCommands(t *testing.T) {
+	monitor := 2
+	percent := 25
+	for _, test := range []struct {
+		name       string
+		eo         *editorOpts
+		fos        []*fileOpts
+		wantBasic  string
+		wantDaemon string
+	}{
+		{
+			name:       "terminal mode",
+			eo:         &editorOpts{},
+			fos:        []*fileOpts{{name: "a.go", lineNumber: 3}},
+			wantBasic:  "emacs --no-window-system +3 a.go",
+			wantDaemon: `emacsclient -t -e '(progn (find-file "a.go")(goto-line 3))'`,
+		},
+		{
+			name:       "eval form",
+			eo:         &editorOpts{evals: []string{"(flymake-mode)"}},
+			fos:        []*fileOpts{{name: "a.go", lineNumber: 3}},
+			wantBasic:  "emacs --no-window-system +3 a.go --eval '(flymake-mode)'",
+			wantDaemon: `emacsclient -t -e '(progn (find-file "a.go")(goto-line 3)(flymake-mode))'`,
+		},
+		{
+			name:       "multiple eval forms with quotes",
+			eo:         &editorOpts{evals: []string{"(flymake-mode)", "(setq x 'y)"}},
+			fos:        []*fileOpts{{name: "a.go"}},
+			wantBasic:  `emacs --no-window-system a.go --eval '(flymake-mode)' --eval '(setq x '\''y)'`,
+			wantDaemon: `emacsclient -t -e '(progn (find-file "a.go")(flymake-mode)(setq x '\''y))'`,
+		},
+		{
+			name:       "line and column",
+			eo:         &editorOpts{},
+			fos:        []*fileOpts{{name: "a.go", lineNumber: 32, column: 8}},
+			wantBasic:  "emacs --no-window-system +32:8 a.go",
+			wantDaemon: `emacsclient -t -e '(progn (find-file "a.go")(goto-line 32)(move-to-column 8))'`,
+		},
+		{
+			name:       "read-only file with line and column",
+			eo:         &editorOpts{},
+			fos:        []*fileOpts{{name: "a.go", lineNumber: 32, column: 8, readOnly: true}},
+			wantBasic:  `emacs --no-window-system --eval '(progn (find-file-read-only "a.go") (goto-line 32) (move-to-column 8))'`,
+			wantDaemon: `emacsclient -t -e '(progn (find-file-read-only "a.go")(goto-line 32)(move-to-column 8))'`,
+		},
+		{
+			name:       "configured binary",
+			eo:         &editorOpts{binary: "/opt/homebrew/bin/emacs"},
+			fos:        []*fileOpts{{name: "a.go"}},
+			wantBasic:  "/opt/homebrew/bin/emacs --no-window-system a.go",
+			wantDaemon: `/opt/homebrew/bin/emacsclient -t -e '(progn (find-file "a.go"))'`,
+		},
+		{
+			name:       "symbol",
+			eo:         &editorOpts{},
+			fos:        []*fileOpts{{name: "a.go", symbol: "InitServer"}, {name: "b.go", lineNumber: 3, symbol: "Run"}},
+			wantBasic:  `emacs --no-window-system --eval '(progn (find-file "b.go") (goto-line 3) (re-search-forward "Run"))' --eval '(progn (find-file "a.go") (goto-char (point-min)) (re-search-forward "InitServer"))' ` + splitEval("b.go"),
+			wantDaemon: `emacsclient -t -e '(progn (find-file "a.go")(goto-char (point-min))(re-search-forward "InitServer")(find-file-other-window "b.go")(goto-line 3)(re-search-forward "Run")(other-window 1))'`,
+		},
+		{
+			name:       "end of file",
+			eo:         &editorOpts{},
+			fos:        []*fileOpts{{name: "a.log", end: true}},
+			wantBasic:  `emacs --no-window-system --eval '(progn (find-file "a.log") (goto-char (point-max)))'`,
+			wantDaemon: `emacsclient -t -e '(progn (find-file "a.log")(goto-char (point-max)))'`,
+		},
+		{
+			name:       "percentage",
+			eo:         &editorOpts{},
+			fos:        []*fileOpts{{name: "a.json", percent: &percent}},
+			wantBasic:  `emacs --no-window-system --eval '(progn (find-file "a.json") (goto-char (/ (* 25 (point-max)) 100)))'`,
+			wantDaemon: `emacsclient -t -e '(progn (find-file "a.json")(goto-char (/ (* 25 (point-max)) 100)))'`,
+		},
+		{
+			name:       "negative line number",
+			eo:         &editorOpts{},
+			fos:        []*fileOpts{{name: "a.log", lineNumber: -5, column: 2}},
+			wantBasic:  `emacs --no-window-system --eval '(progn (find-file "a.log") (goto-char (point-max)) (forward-line -5) (move-to-column 2))'`,
+			wantDaemon: `emacsclient -t -e '(progn (find-file "a.log")(goto-char (point-max))(forward-line -5)(move-to-column 2))'`,
+		},
+		{
+			name:       "two files are split side-by-side",
+			eo:         &editorOpts{},
+			fos:        []*fileOpts{{name: "a.go"}, {name: "b.go"}},
+			wantBasic:  `emacs --no-window-system b.go a.go ` + splitEval("b.go"),
+			wantDaemon: `emacsclient -t -e '(progn (find-file "a.go")(find-file-other-window "b.go")(other-window 1))'`,
+		},
+		{
+			name:       "two files in normal order",
+			eo:         &editorOpts{normalOrder: true},
+			fos:        []*fileOpts{{name: "a.go"}, {name: "b.go", lineNumber: 3}},
+			wantBasic:  `emacs --no-window-system a.go +3 b.go ` + splitEval("a.go"),
+			wantDaemon: `emacsclient -t -e '(progn (find-file "b.go")(goto-line 3)(find-file-other-window "a.go")(other-window 1))'`,
+		},
+		{
+			name:       "three files are not split",
+			eo:         &editorOpts{},
+			fos:        []*fileOpts{{name: "a.go"}, {name: "b.go"}, {name: "c.go"}},
+			wantBasic:  `emacs --no-window-system c.go b.go a.go`,
+			wantDaemon: `emacsclient -t -e '(progn (find-file "a.go")(find-file-other-window "b.go")(find-file-other-window "c.go"))'`,
+		},
+		{
+			name:       "gui mode positions frame on monitor",
+			eo:         &editorOpts{gui: true, monitor: &monitor},
+			fos:        []*fileOpts{{name: "a.go"}},
+			wantBasic:  fmt.Sprintf("emacs --eval '%s' a.go", monitorElisp(2)),
+			wantDaemon: fmt.Sprintf(`emacsclient -c -e '(progn %s(find-file "a.go"))'`, monitorElisp(2)),
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := basic(test.eo, test.fos...)
+			if err != nil {
+				t.Fatalf("basic(%v, %v) returned error: %v", test.eo, test.fos, err)
+			}
+			if diff := cmp.Diff(test.wantBasic, got); diff != "" {
+				t.Errorf("basic(%v, %v) returned diff (-want, +got):\n%s", test.eo, test.fos, diff)
+			}
+
+			got, err = daemon(test.eo, test.fos...)
+			if err != nil {
+				t.Fatalf("daemon(%v, %v) returned error: %v", test.eo, test.fos, err)
+			}
+			if diff := cmp.Diff(test.wantDaemon, got); diff != "" {
+				t.Errorf("daemon(%v, %v) returned diff (-want, +got):\n%s", test.eo, test.fos, diff)
+			}
+		})
+	}
+}
+
+func TestAliasDotElOut(t *testing.T) {
+	dir, err := ioutil.TempDir("", "emacs_el")
+	if err != nil {
+		t.Fatalf("ioutil.TempDir() returned error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	out := filepath.Join(dir, "emacs.d", "aliases.el")
+
+	e := &Emacs{
+		Aliases: map[string]map[string][]string{
+			fileAliaserName: {
+				"salt": {"/compounds/sodiumChloride"},
+			},
+		},
+	}
+	command.ExecuteTest(t, &command.ExecuteTestCase{
+		Node: e.Node(),
+		Args: []string{"el", "--out", out},
+		WantData: &command.Data{
+			Values: map[string]*command.Value{
+				outFlag.Name(): command.StringValue(out),
+			},
+		},
+		WantStdout: []string{fmt.Sprintf("Aliases written to %s", out)},
+	}, nil)
+
+	b, err := ioutil.ReadFile(out)
+	if err != nil {
+		t.Fatalf("ioutil.ReadFile(%s) returned error: %v", out, err)
+	}
+	want := strings.Join([]string{
+		"(setq aliasMap",
+		"#s(hash-table",
+		"size 1",
+		"test equal",
+		"data (",
+		`"salt" "/compounds/sodiumChloride"`,
+		")))",
+		"",
+		`(global-set-key (kbd "C-x C-j") (lambda () (interactive)`,
+		`(setq a (read-string "Alias: "))`,
+		`(setq v (gethash a aliasMap))`,
+		`(cond ((stringp v) (find-file v)) (v (mapc (quote find-file) v)) (t (message "Unknown alias: %s" a)))`,
+		"))",
+	}, "\n")
+	if diff := cmp.Diff(want, string(b)); diff != "" {
+		t.Errorf("el --out wrote incorrect contents (-want, +got):\n%s", diff)
+	}
+}
+
+func TestTouch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "emacs_touch")
+	if err != nil {
+		t.Fatalf("ioutil.TempDir() returned error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	f := filepath.Join(dir, "sub", "new.txt")
+
+	e := &Emacs{}
+	command.ExecuteTest(t, &command.ExecuteTestCase{
+		Node: e.Node(),
+		Args: []string{f, "--touch"},
+		WantData: &command.Data{
+			Values: map[string]*command.Value{
+				emacsArg:         command.StringListValue(f),
+				touchFlag.Name(): command.BoolValue(true),
+			},
+		},
+		WantExecuteData: &command.ExecuteData{
+			Executable: []string{
+				fmt.Sprintf("emacs --no-window-system %s", f),
+			},
+		},
+	}, nil)
+
+	fi, err := os.Stat(f)
+	if err != nil {
+		t.Fatalf("os.Stat(%s) returned error: %v", f, err)
+	}
+	if fi.Size() != 0 {
+		t.Errorf("--touch created file with size %d; want 0", fi.Size())
+	}
+}
+
+func TestDoctor(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		e        *Emacs
+		paths    map[string]string
+		runs     map[string][]string
+		etc      *command.ExecuteTestCase
+		wantRuns []string
+	}{
+		{
+			name: "all checks pass",
+			paths: map[string]string{
+				"emacs":       "/usr/bin/emacs",
+				"emacsclient": "/usr/bin/emacsclient",
+			},
+			runs: map[string][]string{
+				"emacs --version":                     {"GNU Emacs 27.1", "Copyright"},
+				"emacsclient -e '(server-running-p)'": {"t", ""},
+				`emacsclient -e '(expand-file-name server-name server-socket-dir)'`: {`"/tmp/emacs1000/server"`, ""},
+				"emacs --batch -l": nil,
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"doctor"},
+				WantStdout: []string{
+					"[PASS] emacs binary: /usr/bin/emacs (GNU Emacs 27.1)",
+					"[PASS] emacsclient binary: /usr/bin/emacsclient",
+					"[PASS] emacs daemon: running",
+					"[PASS] daemon socket: /tmp/emacs1000/server",
+					"[PASS] alias file: loadable",
+				},
+			},
+		},
+		{
+			name: "reports failed checks",
+			paths: map[string]string{
+				"emacs": "/usr/bin/emacs",
+			},
+			runs: map[string][]string{
+				"emacs --version":  {"GNU Emacs 27.1", ""},
+				"emacs --batch -l": nil,
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"doctor"},
+				WantStdout: []string{
+					"[PASS] emacs binary: /usr/bin/emacs (GNU Emacs 27.1)",
+					`[FAIL] emacsclient binary: exec: "emacsclient": executable file not found in $PATH`,
+					"[FAIL] emacs daemon: daemon is not running",
+					"[FAIL] daemon socket: failed to get socket path: failed to run command: exit status 1",
+					"[PASS] alias file: loadable",
+				},
+				WantStderr: []string{"3 checks failed"},
+				WantErr:    fmt.Errorf("3 checks failed"),
+			},
+		},
+		{
+			name: "uses configured binary and socket",
+			e: &Emacs{
+				Binary: "/opt/emacs/bin/emacs",
+				Socket: "work",
+			},
+			paths: map[string]string{
+				"/opt/emacs/bin/emacs":       "/opt/emacs/bin/emacs",
+				"/opt/emacs/bin/emacsclient": "/opt/emacs/bin/emacsclient",
+			},
+			runs: map[string][]string{
+				"/opt/emacs/bin/emacs --version":                                                           {"GNU Emacs 28.1", ""},
+				"/opt/emacs/bin/emacsclient -s work -e '(server-running-p)'":                               {"t", ""},
+				`/opt/emacs/bin/emacsclient -s work -e '(expand-file-name server-name server-socket-dir)'`: {`"/tmp/emacs1000/work"`, ""},
+				"/opt/emacs/bin/emacs --batch -l":                                                          nil,
+			},
+			etc: &command.ExecuteTestCase{
+				Args: []string{"doctor"},
+				WantStdout: []string{
+					"[PASS] emacs binary: /opt/emacs/bin/emacs (GNU Emacs 28.1)",
+					"[PASS] emacsclient binary: /opt/emacs/bin/emacsclient",
+					"[PASS] emacs daemon: running",
+					"[PASS] daemon socket: /tmp/emacs1000/work",
+					"[PASS] alias file: loadable",
+				},
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			oldLookPath := lookPath
+			lookPath = func(file string) (string, error) {
+				if p, ok := test.paths[file]; ok {
+					return p, nil
+				}
+				return "", &exec.Error{Name: file, Err: exec.ErrNotFound}
+			}
+			defer func() { lookPath = oldLookPath }()
+
+			oldRun := run
+			run = func(contents []string) ([]string, error) {
+				c := strings.Join(contents, "\n")
+				for k, v := range test.runs {
+					if strings.HasPrefix(c, k) {
+						return v, nil
+					}
+				}
+				return nil, fmt.Errorf("failed to run command: exit status 1")
+			}
+			defer func() { run = oldRun }()
+
+			if test.e == nil {
+				test.e = &Emacs{}
+			}
+			test.etc.Node = test.e.Node()
+			command.ExecuteTest(t, test.etc, nil)
+		})
+	}
+}
+
+func TestDaemonRunning(t *testing.T) {
+	for _, test := range []struct {
+		name      string
+		socket    string
+		env       map[string]string
+		fileInfos map[string]os.FileInfo
+		want      bool
+	}{
+		{
+			name: "default socket in user directory",
+			fileInfos: map[string]os.FileInfo{
+				"/tmp/emacs1000/server": fakeFileInfo{mode: os.ModeSocket},
+			},
+			want: true,
+		},
+		{
+			name:   "named socket in user directory",
+			socket: "work",
+			fileInfos: map[string]os.FileInfo{
+				"/tmp/emacs1000/work": fakeFileInfo{mode: os.ModeSocket},
+			},
+			want: true,
+		},
+		{
+			name: "socket of another user",
+			fileInfos: map[string]os.FileInfo{
+				"/tmp/emacs1000/server": nil,
+				"/tmp/emacs0/server":    fakeFileInfo{mode: os.ModeSocket},
+			},
+		},
+		{
+			name: "socket in runtime directory",
+			env: map[string]string{
+				"XDG_RUNTIME_DIR": "/run/user/1000",
+			},
+			fileInfos: map[string]os.FileInfo{
+				"/run/user/1000/emacs/server": fakeFileInfo{mode: os.ModeSocket},
+			},
+			want: true,
+		},
+		{
+			name:   "absolute socket",
+			socket: "/var/run/emacs.sock",
+			fileInfos: map[string]os.FileInfo{
+				"/var/run/emacs.sock": fakeFileInfo{mode: os.ModeSocket},
+			},
+			want: true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			oldGetuid := getuid
+			getuid = func() int { return 1000 }
+			defer func() { getuid = oldGetuid }()
+			oldGetenv := getenv
+			getenv = func(key string) string { return test.env[key] }
+			defer func() { getenv = oldGetenv }()
+
+			e := &Emacs{
+				Socket: test.socket,
+				fs:     &fakeFileSystem{fileInfos: test.fileInfos},
+			}
+			if got := e.daemonRunning(); got != test.want {
+				t.Errorf("daemonRunning() returned %v; want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseFileLine(t *testing.T) {
+	for _, test := range []struct {
+		s       string
+		want    *fileOpts
+		wantErr string
+	}{
+		{
+			s:    "alpha.go",
+			want: &fileOpts{name: absPath(t, "..", "alpha.go")},
+		},
+		{
+			s:    "alpha.go:12",
+			want: &fileOpts{name: absPath(t, "..", "alpha.go"), lineNumber: 12},
+		},
+		{
+			s:    "alpha.go\t12",
+			want: &fileOpts{name: absPath(t, "..", "alpha.go"), lineNumber: 12},
+		},
+		{
+			s:    "/ssh:host:/etc/hosts",
+			want: &fileOpts{name: "/ssh:host:/etc/hosts"},
+		},
+		{
+			s:    "/ssh:host:/etc/hosts:4",
+			want: &fileOpts{name: "/ssh:host:/etc/hosts", lineNumber: 4},
+		},
+		{
+			s:       "alpha.go\ttwelve",
+			wantErr: `invalid line number in "alpha.go\ttwelve"`,
+		},
+	} {
+		t.Run(test.s, func(t *testing.T) {
+			got, err := parseFileLine(test.s)
+			if test.wantErr != "" {
+				if err == nil || err.Error() != test.wantErr {
+					t.Fatalf("parseFileLine(%q) returned error %v; want %q", test.s, err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseFileLine(%q) returned error: %v", test.s, err)
+			}
+			if diff := cmp.Diff(test.want, got, cmp.AllowUnexported(fileOpts{})); diff != "" {
+				t.Errorf("parseFileLine(%q) returned diff (-want, +got):\n%s", test.s, diff)
+			}
 		})
 	}
 }
 
-type fakeFileInfo struct{ mode os.FileMode }
+func historyOf(n int, files ...string) []*historyEntry {
+	var r []*historyEntry
+	for i := 0; i < n; i++ {
+		r = append(r, &historyEntry{Files: files})
+	}
+	return r
+}
+
+type fakeClipboard struct {
+	copied []string
+}
+
+func (fc *fakeClipboard) Copy(s string) error {
+	fc.copied = append(fc.copied, s)
+	return nil
+}
+
+// fakeFileSystem records the directories and files that are created instead
+// of creating them.
+type fakeFileSystem struct {
+	fileInfos map[string]os.FileInfo
+	globs     map[string][]string
+	mkdirs    []string
+	creates   []string
+}
+
+func (ffs *fakeFileSystem) Stat(name string) (os.FileInfo, error) {
+	if fi, ok := ffs.fileInfos[name]; ok {
+		if fi == nil {
+			return nil, &os.PathError{Op: "stat", Path: name, Err: os.ErrNotExist}
+		}
+		return fi, nil
+	}
+	return os.Stat(name)
+}
+
+func (ffs *fakeFileSystem) EvalSymlinks(path string) (string, error) {
+	if _, ok := ffs.fileInfos[path]; ok {
+		return path, nil
+	}
+	return filepath.EvalSymlinks(path)
+}
+
+func (ffs *fakeFileSystem) Walk(root string, fn filepath.WalkFunc) error {
+	return filepath.Walk(root, fn)
+}
+
+func (ffs *fakeFileSystem) Open(name string) (io.ReadCloser, error) {
+	if fi, ok := ffs.fileInfos[name]; ok && fi == nil {
+		return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+	}
+	return os.Open(name)
+}
+
+func (ffs *fakeFileSystem) WriteFile(name string, data []byte, perm os.FileMode) error {
+	return ioutil.WriteFile(name, data, perm)
+}
+
+func (ffs *fakeFileSystem) MkdirAll(dir string, _ os.FileMode) error {
+	ffs.mkdirs = append(ffs.mkdirs, dir)
+	return nil
+}
+
+func (ffs *fakeFileSystem) Create(name string) error {
+	ffs.creates = append(ffs.creates, name)
+	return nil
+}
+
+func (ffs *fakeFileSystem) Glob(pattern string) ([]string, error) {
+	if m, ok := ffs.globs[pattern]; ok {
+		return m, nil
+	}
+	return filepath.Glob(pattern)
+}
+
+type fakeFileInfo struct {
+	mode os.FileMode
+	size int64
+	sys  interface{}
+}
 
 func (fi fakeFileInfo) Name() string       { return "" }
-func (fi fakeFileInfo) Size() int64        { return 0 }
+func (fi fakeFileInfo) Size() int64        { return fi.size }
 func (fi fakeFileInfo) Mode() os.FileMode  { return fi.mode }
 func (fi fakeFileInfo) ModTime() time.Time { return time.Now() }
 func (fi fakeFileInfo) IsDir() bool        { return fi.Mode().IsDir() }
-func (fi fakeFileInfo) Sys() interface{}   { return nil }
+func (fi fakeFileInfo) Sys() interface{}   { return fi.sys }
 
 /*func TestUsage(t *testing.T) {
 	e := &Emacs{}
@@ -1042,6 +8636,12 @@ func TestMetadata(t *testing.T) {
 	}
 }
 
+// splitEval returns the basic mode argument for showing the provided file in
+// a window to the right.
+func splitEval(name string) string {
+	return fmt.Sprintf(`--eval '(progn (split-window-right) (other-window 1) (switch-to-buffer (get-file-buffer "%s")) (other-window 1))'`, name)
+}
+
 func absPath(t *testing.T, sl ...string) string {
 	t.Helper()
 	r, err := filepath.Abs(path(sl...))
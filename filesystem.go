@@ -0,0 +1,59 @@
+package emacs
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// fileSystem is the filesystem that files are checked, read, and created in.
+type fileSystem interface {
+	Stat(name string) (os.FileInfo, error)
+	EvalSymlinks(path string) (string, error)
+	Walk(root string, fn filepath.WalkFunc) error
+	Glob(pattern string) ([]string, error)
+	Open(name string) (io.ReadCloser, error)
+	MkdirAll(dir string, perm os.FileMode) error
+	// Create creates an empty file (and fails if it already exists).
+	Create(name string) error
+	WriteFile(name string, data []byte, perm os.FileMode) error
+}
+
+type osFileSystem struct{}
+
+func (*osFileSystem) Stat(name string) (os.FileInfo, error) {
+	return os.Stat(name)
+}
+
+func (*osFileSystem) EvalSymlinks(path string) (string, error) {
+	return filepath.EvalSymlinks(path)
+}
+
+func (*osFileSystem) Walk(root string, fn filepath.WalkFunc) error {
+	return filepath.Walk(root, fn)
+}
+
+func (*osFileSystem) Glob(pattern string) ([]string, error) {
+	return filepath.Glob(pattern)
+}
+
+func (*osFileSystem) Open(name string) (io.ReadCloser, error) {
+	return os.Open(name)
+}
+
+func (*osFileSystem) MkdirAll(dir string, perm os.FileMode) error {
+	return os.MkdirAll(dir, perm)
+}
+
+func (*osFileSystem) Create(name string) error {
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_EXCL, 0666)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+func (*osFileSystem) WriteFile(name string, data []byte, perm os.FileMode) error {
+	return ioutil.WriteFile(name, data, perm)
+}
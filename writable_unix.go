@@ -0,0 +1,46 @@
+//go:build !windows
+// +build !windows
+
+package emacs
+
+import (
+	"os"
+	"syscall"
+)
+
+// writable returns whether or not the current user can write to the file.
+// The owner, group, or other write bit is checked depending on who owns
+// the file.
+func writable(fi os.FileInfo) bool {
+	perm := fi.Mode().Perm()
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return perm&0222 != 0
+	}
+
+	uid := getuid()
+	if uid == 0 {
+		return true
+	}
+	if int(st.Uid) == uid {
+		return perm&0200 != 0
+	}
+	if inGroup(int(st.Gid)) {
+		return perm&0020 != 0
+	}
+	return perm&0002 != 0
+}
+
+// inGroup returns whether or not the current user is in the provided group.
+func inGroup(gid int) bool {
+	groups, err := getgroups()
+	if err != nil {
+		return false
+	}
+	for _, g := range groups {
+		if g == gid {
+			return true
+		}
+	}
+	return false
+}
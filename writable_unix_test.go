@@ -0,0 +1,73 @@
+//go:build !windows
+// +build !windows
+
+package emacs
+
+import (
+	"os"
+	"syscall"
+	"testing"
+)
+
+func TestWritable(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		fi   os.FileInfo
+		uid  int
+		want bool
+	}{
+		{
+			name: "owner can write",
+			fi:   fakeFileInfo{mode: 0644, sys: &syscall.Stat_t{Uid: 1000, Gid: 1000}},
+			uid:  1000,
+			want: true,
+		},
+		{
+			name: "owner can't write without owner write bit",
+			fi:   fakeFileInfo{mode: 0466, sys: &syscall.Stat_t{Uid: 1000, Gid: 1000}},
+			uid:  1000,
+		},
+		{
+			name: "other user can't write file owned by root",
+			fi:   fakeFileInfo{mode: 0644, sys: &syscall.Stat_t{Uid: 0, Gid: 0}},
+			uid:  1000,
+		},
+		{
+			name: "group member can write with group write bit",
+			fi:   fakeFileInfo{mode: 0664, sys: &syscall.Stat_t{Uid: 0, Gid: 20}},
+			uid:  1000,
+			want: true,
+		},
+		{
+			name: "other user can write with other write bit",
+			fi:   fakeFileInfo{mode: 0646, sys: &syscall.Stat_t{Uid: 0, Gid: 0}},
+			uid:  1000,
+			want: true,
+		},
+		{
+			name: "root can write anything",
+			fi:   fakeFileInfo{mode: 0444, sys: &syscall.Stat_t{Uid: 1000, Gid: 1000}},
+			uid:  0,
+			want: true,
+		},
+		{
+			name: "checks write bits without ownership info",
+			fi:   fakeFileInfo{mode: 0644},
+			uid:  1000,
+			want: true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			oldGetuid := getuid
+			getuid = func() int { return test.uid }
+			defer func() { getuid = oldGetuid }()
+			oldGetgroups := getgroups
+			getgroups = func() ([]int, error) { return []int{20, 1000}, nil }
+			defer func() { getgroups = oldGetgroups }()
+
+			if got := writable(test.fi); got != test.want {
+				t.Errorf("writable(%v) returned %v; want %v", test.fi, got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,11 @@
+package emacs
+
+import (
+	"os"
+)
+
+// writable returns whether or not the file can be written to. Windows file
+// modes don't include ownership, so only the write bits are checked.
+func writable(fi os.FileInfo) bool {
+	return fi.Mode().Perm()&0222 != 0
+}